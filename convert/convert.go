@@ -0,0 +1,22 @@
+// Package convert converts rendered documents to PDF with pluggable
+// backends, a local LibreOffice or a conversion service over HTTP
+package convert
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrConversionFailed = errors.New("conversion failed")
+
+// Converter converts a docx document to PDF
+type Converter interface {
+	Convert(ctx context.Context, docx []byte) ([]byte, error)
+}
+
+// ConverterFunc is a func used as a Converter, such as a stand-in in tests
+type ConverterFunc func(ctx context.Context, docx []byte) ([]byte, error)
+
+func (f ConverterFunc) Convert(ctx context.Context, docx []byte) ([]byte, error) {
+	return f(ctx, docx)
+}
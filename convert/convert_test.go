@@ -0,0 +1,121 @@
+package convert
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestHTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/forms/libreoffice/convert" {
+			f, hdr, err := r.FormFile("files")
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			docx, _ := io.ReadAll(f)
+			w.Write(append([]byte("%PDF "+hdr.Filename+" "+r.FormValue("pdfa")+" "), docx...))
+			return
+		}
+		if r.FormValue("convert-to") != "pdf" {
+			http.Error(w, "unsupported format", http.StatusBadRequest)
+			return
+		}
+		f, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		docx, _ := io.ReadAll(f)
+		w.Write(append([]byte("%PDF "), docx...))
+	}))
+	defer srv.Close()
+
+	Convey("Test HTTP: Gotenberg", t, func() {
+		c := Gotenberg(srv.URL + "/")
+		c.Form = map[string]string{"pdfa": "PDF/A-2b"}
+		pdf, err := c.Convert(context.Background(), []byte("docx"))
+		So(err, ShouldBeNil)
+		So(string(pdf), ShouldEqual, "%PDF document.docx PDF/A-2b docx")
+	})
+
+	Convey("Test HTTP: Unoserver", t, func() {
+		pdf, err := Unoserver(srv.URL+"/request").Convert(context.Background(), []byte("docx"))
+		So(err, ShouldBeNil)
+		So(string(pdf), ShouldEqual, "%PDF docx")
+	})
+
+	Convey("Test HTTP: Failed Conversions", t, func() {
+		c := &HTTP{URL: srv.URL + "/request", Field: "file"}
+		_, err := c.Convert(context.Background(), []byte("docx"))
+		So(errors.Is(err, ErrConversionFailed), ShouldBeTrue)
+		So(err.Error(), ShouldEqual, "conversion failed: 400 Bad Request: unsupported format")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = Gotenberg(srv.URL).Convert(ctx, []byte("docx"))
+		So(err, ShouldEqual, context.Canceled)
+	})
+}
+
+// standIn writes a shell script standing in for LibreOffice
+func standIn(t *testing.T, script string) string {
+	bin := filepath.Join(t.TempDir(), "lowriter")
+	if err := os.WriteFile(bin, []byte("#!/bin/sh\n"+script), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	return bin
+}
+
+func TestLibreOffice(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stand-in binary is a shell script")
+	}
+
+	// the stand-in copies the document to the pdf file LibreOffice would
+	// write to the output directory
+	bin := standIn(t, "[ \"$1 $2 $3 $4\" = \"--headless --convert-to pdf --outdir\" ] || exit 2\n"+
+		"cp \"$6\" \"$5/document.pdf\"\n")
+
+	Convey("Test LibreOffice: Conversion", t, func() {
+		tmp := t.TempDir()
+		pdf, err := LibreOffice{Binary: bin, TempDir: tmp}.Convert(context.Background(), []byte("docx"))
+		So(err, ShouldBeNil)
+		So(string(pdf), ShouldEqual, "docx")
+
+		entries, err := os.ReadDir(tmp)
+		So(err, ShouldBeNil)
+		So(entries, ShouldBeEmpty)
+	})
+
+	Convey("Test LibreOffice: Failures Carry Standard Error", t, func() {
+		tmp := t.TempDir()
+		failing := standIn(t, "echo 'Error: source file could not be loaded' >&2\nexit 1\n")
+		_, err := LibreOffice{Binary: failing, TempDir: tmp}.Convert(context.Background(), []byte("docx"))
+		So(errors.Is(err, ErrConversionFailed), ShouldBeTrue)
+		So(err.Error(), ShouldEndWith, "exit status 1: Error: source file could not be loaded")
+
+		silent := standIn(t, "echo 'Error: source file could not be loaded' >&2\n")
+		_, err = LibreOffice{Binary: silent, TempDir: tmp}.Convert(context.Background(), []byte("docx"))
+		So(errors.Is(err, ErrConversionFailed), ShouldBeTrue)
+		So(err.Error(), ShouldEndWith, "Error: source file could not be loaded")
+
+		entries, err := os.ReadDir(tmp)
+		So(err, ShouldBeNil)
+		So(entries, ShouldBeEmpty)
+	})
+
+	Convey("Test LibreOffice: Missing Binary", t, func() {
+		_, err := LibreOffice{Binary: filepath.Join(t.TempDir(), "missing")}.Convert(context.Background(), []byte("docx"))
+		So(errors.Is(err, ErrConversionFailed), ShouldBeTrue)
+	})
+}
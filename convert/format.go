@@ -0,0 +1,126 @@
+package convert
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("format not supported by the converter")
+	ErrInvalidOptions    = errors.New("invalid conversion options")
+)
+
+// Format is a format documents are converted to
+type Format int
+
+const (
+	PDF Format = iota
+	ODT
+	HTML
+	TXT
+	// PNG is an image of the first page
+	PNG
+)
+
+func (f Format) String() string {
+	switch f {
+	case PDF:
+		return "PDF"
+	case ODT:
+		return "ODT"
+	case HTML:
+		return "HTML"
+	case TXT:
+		return "TXT"
+	case PNG:
+		return "PNG"
+	}
+	return "Format(" + strconv.Itoa(int(f)) + ")"
+}
+
+// Ext returns the file extension of the format, without the dot
+func (f Format) Ext() string {
+	switch f {
+	case PDF, ODT, HTML, TXT, PNG:
+		return strings.ToLower(f.String())
+	}
+	return ""
+}
+
+// Options are the options of the formats documents are converted to, only
+// the ones of the format of a conversion apply
+type Options struct {
+	PDF   PDFOptions
+	Text  TextOptions
+	Image ImageOptions
+}
+
+// TextOptions are the options of plain text
+type TextOptions struct {
+	// Encoding is the name of the character set LibreOffice encodes the text
+	// with, UTF8 by default
+	Encoding string
+	// CRLF ends lines with CR LF rather than LF
+	CRLF bool
+}
+
+// ImageOptions are the options of page images, zero sizes keep the size of
+// the page and a single zero size keeps its aspect ratio
+type ImageOptions struct {
+	Width  int
+	Height int
+}
+
+// FormatConverter is a Converter converting documents to other formats than
+// PDF as well
+type FormatConverter interface {
+	Converter
+	ConvertTo(ctx context.Context, docx []byte, format Format, opts Options) ([]byte, error)
+}
+
+// convertTo returns the --convert-to argument of LibreOffice for the format,
+// HTML embeds the images of the document
+func (o Options) convertTo(f Format) (string, error) {
+	switch f {
+	case PDF:
+		if err := o.PDF.Validate(); err != nil {
+			return "", err
+		}
+		return o.PDF.convertTo()
+	case ODT:
+		return "odt", nil
+	case HTML:
+		return "html:HTML (StarWriter):EmbedImages", nil
+	case TXT:
+		enc := o.Text.Encoding
+		if enc == "" {
+			enc = "UTF8"
+		}
+		eol := "LF"
+		if o.Text.CRLF {
+			eol = "CRLF"
+		}
+		return "txt:Text (encoded):" + enc + "," + eol, nil
+	case PNG:
+		if o.Image.Width < 0 || o.Image.Height < 0 {
+			return "", fmt.Errorf("%w: image size %dx%d", ErrInvalidOptions, o.Image.Width, o.Image.Height)
+		}
+		props := map[string]filterValue{}
+		if o.Image.Width != 0 {
+			props["PixelWidth"] = filterValue{Type: "long", Value: strconv.Itoa(o.Image.Width)}
+		}
+		if o.Image.Height != 0 {
+			props["PixelHeight"] = filterValue{Type: "long", Value: strconv.Itoa(o.Image.Height)}
+		}
+		if len(props) == 0 {
+			return "png", nil
+		}
+		b, _ := json.Marshal(props)
+		return "png:writer_png_Export:" + string(b), nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnsupportedFormat, f)
+}
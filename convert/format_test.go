@@ -0,0 +1,71 @@
+package convert
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFormats(t *testing.T) {
+	Convey("Test Formats: LibreOffice Conversion Arguments", t, func() {
+		for format, want := range map[Format]string{
+			PDF:  "pdf",
+			ODT:  "odt",
+			HTML: "html:HTML (StarWriter):EmbedImages",
+			TXT:  "txt:Text (encoded):UTF8,LF",
+			PNG:  "png",
+		} {
+			convertTo, err := Options{}.convertTo(format)
+			So(err, ShouldBeNil)
+			So(convertTo, ShouldEqual, want)
+		}
+
+		convertTo, err := Options{Text: TextOptions{Encoding: "UTF16", CRLF: true}}.convertTo(TXT)
+		So(err, ShouldBeNil)
+		So(convertTo, ShouldEqual, "txt:Text (encoded):UTF16,CRLF")
+		convertTo, err = Options{Image: ImageOptions{Width: 320}}.convertTo(PNG)
+		So(err, ShouldBeNil)
+		So(convertTo, ShouldEqual, `png:writer_png_Export:{"PixelWidth":{"type":"long","value":"320"}}`)
+
+		_, err = Options{Image: ImageOptions{Height: -1}}.convertTo(PNG)
+		So(errors.Is(err, ErrInvalidOptions), ShouldBeTrue)
+		_, err = Options{}.convertTo(Format(9))
+		So(errors.Is(err, ErrUnsupportedFormat), ShouldBeTrue)
+		So(Format(9).String(), ShouldEqual, "Format(9)")
+		So(HTML.Ext(), ShouldEqual, "html")
+	})
+
+	Convey("Test Formats: LibreOffice Output Files", t, func() {
+		if runtime.GOOS == "windows" {
+			return
+		}
+		bin := standIn(t, "printf '%s' \"$3\" > \"$5/document.${3%%:*}\"\n")
+		out, err := LibreOffice{Binary: bin, TempDir: t.TempDir()}.ConvertTo(context.Background(), []byte("docx"), ODT, Options{})
+		So(err, ShouldBeNil)
+		So(string(out), ShouldEqual, "odt")
+		out, err = LibreOffice{Binary: bin, TempDir: t.TempDir()}.ConvertTo(context.Background(), []byte("docx"), TXT, Options{})
+		So(err, ShouldBeNil)
+		So(string(out), ShouldEqual, "txt:Text (encoded):UTF8,LF")
+	})
+
+	Convey("Test Formats: HTTP Converters", t, func() {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(r.FormValue("convert-to")))
+		}))
+		defer srv.Close()
+
+		out, err := Unoserver(srv.URL).ConvertTo(context.Background(), []byte("docx"), ODT, Options{})
+		So(err, ShouldBeNil)
+		So(string(out), ShouldEqual, "odt")
+
+		_, err = Unoserver(srv.URL).ConvertTo(context.Background(), []byte("docx"), PNG, Options{Image: ImageOptions{Width: 10}})
+		So(errors.Is(err, ErrUnsupportedFormat), ShouldBeTrue)
+		_, err = Gotenberg(srv.URL).ConvertTo(context.Background(), []byte("docx"), HTML, Options{})
+		So(errors.Is(err, ErrUnsupportedFormat), ShouldBeTrue)
+	})
+}
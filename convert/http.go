@@ -0,0 +1,174 @@
+package convert
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// HTTP converts documents with a conversion service, the document is posted
+// as a multipart form file and the response body is the PDF
+type HTTP struct {
+	URL string
+	// Field is the name of the form field of the document, files by default
+	Field string
+	// Form holds the other fields of the form, such as the options of the
+	// conversion
+	Form   map[string]string
+	Header http.Header
+	// Client is http.DefaultClient by default
+	Client *http.Client
+	// PDFForm returns the form fields of the options of a PDF, options
+	// aren't supported when it's nil
+	PDFForm func(PDFOptions) (map[string]string, error)
+	// FormatField is the name of the form field of the extension of the
+	// format, only PDF is supported when it's empty
+	FormatField string
+}
+
+// Gotenberg creates an HTTP converter for the LibreOffice route of the
+// Gotenberg service at the url
+//
+//	convert.Gotenberg("http://gotenberg:3000")
+func Gotenberg(url string) *HTTP {
+	return &HTTP{URL: strings.TrimSuffix(url, "/") + "/forms/libreoffice/convert", Field: "files", PDFForm: gotenbergForm}
+}
+
+// Unoserver creates an HTTP converter for unoserver REST endpoints taking the
+// document as file and the target format as convert-to
+//
+//	convert.Unoserver("http://unoserver:2004/request")
+func Unoserver(url string) *HTTP {
+	return &HTTP{URL: url, Field: "file", FormatField: "convert-to"}
+}
+
+func (h *HTTP) Convert(ctx context.Context, docx []byte) ([]byte, error) {
+	return h.ConvertPDF(ctx, docx, PDFOptions{})
+}
+
+// ConvertPDF is Convert exporting the PDF with the options, which are sent
+// as the form fields PDFForm returns
+func (h *HTTP) ConvertPDF(ctx context.Context, docx []byte, opts PDFOptions) ([]byte, error) {
+	return h.ConvertTo(ctx, docx, PDF, Options{PDF: opts})
+}
+
+// ConvertTo is Convert converting the document to the format, the options
+// of other formats than PDF aren't supported
+func (h *HTTP) ConvertTo(ctx context.Context, docx []byte, format Format, opts Options) ([]byte, error) {
+	fields, err := h.fields(format, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	body, contentType, err := h.form(docx, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, body)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range h.Header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	client := h.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("%w: %v", ErrConversionFailed, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("%w: %s: %s", ErrConversionFailed, resp.Status, bytes.TrimSpace(msg))
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// fields returns the form fields of the format and its options
+func (h *HTTP) fields(format Format, opts Options) (map[string]string, error) {
+	fields := map[string]string{}
+	switch {
+	case format == PDF && opts.PDF != (PDFOptions{}):
+		if err := opts.PDF.Validate(); err != nil {
+			return nil, err
+		}
+		if h.PDFForm == nil {
+			return nil, ErrUnsupportedPDFOptions
+		}
+		var err error
+		fields, err = h.PDFForm(opts.PDF)
+		if err != nil {
+			return nil, err
+		}
+	case format == TXT && opts.Text != (TextOptions{}), format == PNG && opts.Image != (ImageOptions{}):
+		return nil, fmt.Errorf("%w: options of %s", ErrUnsupportedFormat, format)
+	}
+
+	if format.Ext() == "" || format != PDF && h.FormatField == "" {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+	if h.FormatField != "" {
+		fields[h.FormatField] = format.Ext()
+	}
+	return fields, nil
+}
+
+// form returns the multipart form of the document with the fields of the
+// converter and the extra ones, which take precedence
+func (h *HTTP) form(docx []byte, extra map[string]string) (io.Reader, string, error) {
+	buf := new(bytes.Buffer)
+	w := multipart.NewWriter(buf)
+
+	fields := make(map[string]string, len(h.Form)+len(extra))
+	for name, value := range h.Form {
+		fields[name] = value
+	}
+	for name, value := range extra {
+		fields[name] = value
+	}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		err := w.WriteField(name, fields[name])
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	field := h.Field
+	if field == "" {
+		field = "files"
+	}
+	f, err := w.CreateFormFile(field, "document.docx")
+	if err != nil {
+		return nil, "", err
+	}
+	_, err = f.Write(docx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = w.Close()
+	if err != nil {
+		return nil, "", err
+	}
+	return buf, w.FormDataContentType(), nil
+}
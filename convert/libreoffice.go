@@ -0,0 +1,167 @@
+package convert
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/saman3d/samdoc"
+)
+
+// LibreOffice converts documents with a local LibreOffice, a process is
+// started for every conversion
+type LibreOffice struct {
+	// Binary is the LibreOffice executable, lowriter by default
+	Binary string
+	// TempDir is where the documents are written while they're converted,
+	// samdoc.TEMPDIR by default
+	TempDir string
+	// Timeout bounds every conversion on top of the deadline of its context,
+	// zero means no timeout
+	Timeout time.Duration
+	// Profile is the directory of the LibreOffice user profile, the default
+	// profile of the user when empty. Concurrent conversions sharing a
+	// profile are serialized by LibreOffice.
+	Profile string
+}
+
+// Convert converts the document with a new LibreOffice process. The process
+// and every process it started are killed when the context is done, and the
+// files of the conversion are removed whatever the outcome.
+func (l LibreOffice) Convert(ctx context.Context, docx []byte) ([]byte, error) {
+	return l.ConvertPDF(ctx, docx, PDFOptions{})
+}
+
+// ConvertPDF is Convert exporting the PDF with the options
+func (l LibreOffice) ConvertPDF(ctx context.Context, docx []byte, opts PDFOptions) ([]byte, error) {
+	return l.ConvertTo(ctx, docx, PDF, Options{PDF: opts})
+}
+
+// ConvertTo is Convert converting the document to the format
+func (l LibreOffice) ConvertTo(ctx context.Context, docx []byte, format Format, opts Options) ([]byte, error) {
+	convertTo, err := opts.convertTo(format)
+	if err != nil {
+		return nil, err
+	}
+	return l.convert(ctx, "document.docx", docx, convertTo, "document."+format.Ext())
+}
+
+// convert writes the data to the input file of a temporary directory and
+// converts it, out is the name of the file LibreOffice converts it to
+func (l LibreOffice) convert(ctx context.Context, name string, data []byte, convertTo, out string) ([]byte, error) {
+	if l.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, l.Timeout)
+		defer cancel()
+	}
+
+	dir, err := os.MkdirTemp(l.tempDir(), samdoc.PREFIX)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, name)
+	err = os.WriteFile(input, data, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	stderr := new(bytes.Buffer)
+	cmd := exec.Command(l.binary(), l.args("--headless", "--convert-to", convertTo, "--outdir", dir, input)...)
+	cmd.Dir = dir
+	cmd.Stderr = stderr
+	err = run(ctx, cmd)
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", l.binary(), ctx.Err())
+	}
+	if err != nil {
+		return nil, commandError(l.binary(), err, stderr)
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, out))
+	if err != nil {
+		// LibreOffice exits successfully even when it can't load a document
+		return nil, commandError(l.binary(), err, stderr)
+	}
+	return b, nil
+}
+
+func (l LibreOffice) binary() string {
+	if l.Binary == "" {
+		return "lowriter"
+	}
+	return l.Binary
+}
+
+// args prepends the option of the profile to the arguments
+func (l LibreOffice) args(args ...string) []string {
+	if l.Profile == "" {
+		return args
+	}
+	return append([]string{"-env:UserInstallation=" + fileURL(l.Profile)}, args...)
+}
+
+func fileURL(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(path)}
+	if !strings.HasPrefix(u.Path, "/") {
+		// windows drive letters
+		u.Path = "/" + u.Path
+	}
+	return u.String()
+}
+
+func (l LibreOffice) tempDir() string {
+	if l.TempDir == "" {
+		return samdoc.TEMPDIR
+	}
+	return l.TempDir
+}
+
+// run runs the command in a process group of its own which is killed as a
+// whole when the context is done, LibreOffice launchers start the actual
+// office process as a child
+func run(ctx context.Context, cmd *exec.Cmd) error {
+	setProcessGroup(cmd)
+	err := cmd.Start()
+	if err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+	select {
+	case err = <-done:
+		return err
+	case <-ctx.Done():
+		killProcessGroup(cmd)
+		<-done
+		return ctx.Err()
+	}
+}
+
+// maxStderr is how much of the end of the standard error of a failed
+// command is kept in its error
+const maxStderr = 1024
+
+func commandError(name string, err error, stderr *bytes.Buffer) error {
+	msg := bytes.TrimSpace(stderr.Bytes())
+	if len(msg) > maxStderr {
+		msg = msg[len(msg)-maxStderr:]
+	}
+	if len(msg) == 0 {
+		return fmt.Errorf("%w: %s: %v", ErrConversionFailed, name, err)
+	}
+	return fmt.Errorf("%w: %s: %v: %s", ErrConversionFailed, name, err, msg)
+}
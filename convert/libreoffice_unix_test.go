@@ -0,0 +1,65 @@
+//go:build unix
+
+package convert
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLibreOfficeCancellation(t *testing.T) {
+	// the stand-in hangs in a child process like soffice.bin started by the
+	// lowriter launcher
+	pidfile := filepath.Join(t.TempDir(), "pid")
+	bin := standIn(t, "sleep 30 &\necho $! > "+pidfile+"\nwait\n")
+
+	Convey("Test LibreOffice: Timeouts Kill The Process Tree", t, func() {
+		tmp := t.TempDir()
+		start := time.Now()
+		_, err := LibreOffice{Binary: bin, TempDir: tmp, Timeout: 200 * time.Millisecond}.Convert(context.Background(), []byte("docx"))
+		So(errors.Is(err, context.DeadlineExceeded), ShouldBeTrue)
+		So(time.Since(start), ShouldBeLessThan, 10*time.Second)
+
+		b, err := os.ReadFile(pidfile)
+		So(err, ShouldBeNil)
+		pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+		So(err, ShouldBeNil)
+		So(exited(pid), ShouldBeTrue)
+
+		entries, err := os.ReadDir(tmp)
+		So(err, ShouldBeNil)
+		So(entries, ShouldBeEmpty)
+	})
+
+	Convey("Test LibreOffice: Cancellation", t, func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(100*time.Millisecond, cancel)
+		_, err := LibreOffice{Binary: bin, TempDir: t.TempDir()}.Convert(ctx, []byte("docx"))
+		So(errors.Is(err, context.Canceled), ShouldBeTrue)
+	})
+}
+
+// exited waits a while for the process to exit, zombies left unreaped by the
+// init of containers count as exited
+func exited(pid int) bool {
+	for i := 0; i < 50; i++ {
+		if syscall.Kill(pid, 0) == syscall.ESRCH {
+			return true
+		}
+		stat, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+		if err == nil && strings.Contains(string(stat), ") Z ") {
+			return true
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return false
+}
@@ -0,0 +1,199 @@
+package convert
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+var (
+	ErrInvalidPDFOptions     = errors.New("invalid pdf options")
+	ErrUnsupportedPDFOptions = errors.New("pdf options not supported by the converter")
+)
+
+// PDFA is a PDF/A conformance level
+type PDFA int
+
+const (
+	PDFANone PDFA = iota
+	PDFA1b
+	PDFA2b
+	PDFA3b
+)
+
+func (a PDFA) String() string {
+	switch a {
+	case PDFA1b:
+		return "PDF/A-1b"
+	case PDFA2b:
+		return "PDF/A-2b"
+	case PDFA3b:
+		return "PDF/A-3b"
+	}
+	return ""
+}
+
+// PDFOptions are the options of the PDF a document is exported to, the zero
+// value leaves the defaults of the converter
+type PDFOptions struct {
+	PDFA PDFA
+	// Tagged exports the structure of the document for accessibility
+	Tagged bool
+	// LosslessImages compresses images losslessly rather than as JPEG
+	LosslessImages bool
+	// ImageQuality is the JPEG quality of images, from 1 to 100
+	ImageQuality int
+	// MaxImageResolution reduces images to the resolution in DPI, such as
+	// 150 or 300
+	MaxImageResolution int
+	// PageRange selects the exported pages, such as "1-3,5"
+	PageRange string
+	// OmitBookmarks and OmitFormFields leave the headings out of the
+	// bookmarks and the form fields out of the PDF
+	OmitBookmarks  bool
+	OmitFormFields bool
+	// OpenPassword encrypts the PDF with the password, PDF/A documents
+	// can't be encrypted. Only Gotenberg applies it, LibreOffice and Pool
+	// fail with ErrUnsupportedPDFOptions since soffice only takes it on the
+	// command line, readable by the other users of the host, and
+	// FilterOptions leaves it out.
+	OpenPassword string
+	// Watermark is text drawn across every page
+	Watermark string
+}
+
+// PDFConverter is a Converter taking the options of every PDF it exports
+type PDFConverter interface {
+	Converter
+	ConvertPDF(ctx context.Context, docx []byte, opts PDFOptions) ([]byte, error)
+}
+
+// Validate reports options out of range and conflicting options
+func (o PDFOptions) Validate() error {
+	if o.PDFA < PDFANone || o.PDFA > PDFA3b {
+		return fmt.Errorf("%w: unknown PDF/A level %d", ErrInvalidPDFOptions, o.PDFA)
+	}
+	if o.ImageQuality < 0 || o.ImageQuality > 100 {
+		return fmt.Errorf("%w: image quality %d out of 1 to 100", ErrInvalidPDFOptions, o.ImageQuality)
+	}
+	if o.MaxImageResolution < 0 {
+		return fmt.Errorf("%w: image resolution %d", ErrInvalidPDFOptions, o.MaxImageResolution)
+	}
+	if o.PDFA != PDFANone && o.OpenPassword != "" {
+		return fmt.Errorf("%w: %s documents can't be encrypted", ErrInvalidPDFOptions, o.PDFA)
+	}
+	return nil
+}
+
+// filterValue is a property of the JSON options of a LibreOffice filter
+type filterValue struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// FilterOptions returns the options as the JSON options of the LibreOffice
+// PDF export filter, empty for the zero value. The open password is left out,
+// the options end up on a command line.
+//
+//	soffice --convert-to 'pdf:writer_pdf_Export:{"SelectPdfVersion":{"type":"long","value":"2"}}'
+func (o PDFOptions) FilterOptions() string {
+	props := map[string]filterValue{}
+	boolean := func(name string, v bool) {
+		props[name] = filterValue{Type: "boolean", Value: strconv.FormatBool(v)}
+	}
+	long := func(name string, v int) {
+		props[name] = filterValue{Type: "long", Value: strconv.Itoa(v)}
+	}
+	str := func(name string, v string) {
+		props[name] = filterValue{Type: "string", Value: v}
+	}
+
+	if o.PDFA != PDFANone {
+		// PDF/A-1b, 2b and 3b are the versions 1, 2 and 3 of the filter
+		long("SelectPdfVersion", int(o.PDFA))
+	}
+	if o.Tagged {
+		boolean("UseTaggedPDF", true)
+	}
+	if o.LosslessImages {
+		boolean("UseLosslessCompression", true)
+	}
+	if o.ImageQuality != 0 {
+		long("Quality", o.ImageQuality)
+	}
+	if o.MaxImageResolution != 0 {
+		boolean("ReduceImageResolution", true)
+		long("MaxImageResolution", o.MaxImageResolution)
+	}
+	if o.PageRange != "" {
+		str("PageRange", o.PageRange)
+	}
+	if o.OmitBookmarks {
+		boolean("ExportBookmarks", false)
+	}
+	if o.OmitFormFields {
+		boolean("ExportFormFields", false)
+	}
+	if o.Watermark != "" {
+		str("Watermark", o.Watermark)
+	}
+
+	if len(props) == 0 {
+		return ""
+	}
+	b, _ := json.Marshal(props)
+	return string(b)
+}
+
+// convertTo returns the --convert-to argument of LibreOffice
+func (o PDFOptions) convertTo() (string, error) {
+	if o.OpenPassword != "" {
+		return "", fmt.Errorf("%w: open password on the command line", ErrUnsupportedPDFOptions)
+	}
+	if fo := o.FilterOptions(); fo != "" {
+		return "pdf:writer_pdf_Export:" + fo, nil
+	}
+	return "pdf", nil
+}
+
+// gotenbergForm returns the options as the form fields of the LibreOffice
+// route of Gotenberg, which has no watermarks and only tags PDFs as part of
+// the stricter PDF/UA
+func gotenbergForm(o PDFOptions) (map[string]string, error) {
+	if o.Watermark != "" {
+		return nil, fmt.Errorf("%w: watermark", ErrUnsupportedPDFOptions)
+	}
+	if o.Tagged {
+		return nil, fmt.Errorf("%w: tagged", ErrUnsupportedPDFOptions)
+	}
+
+	form := map[string]string{}
+	if o.PDFA != PDFANone {
+		form["pdfa"] = o.PDFA.String()
+	}
+	if o.LosslessImages {
+		form["losslessImageCompression"] = "true"
+	}
+	if o.ImageQuality != 0 {
+		form["quality"] = strconv.Itoa(o.ImageQuality)
+	}
+	if o.MaxImageResolution != 0 {
+		form["reduceImageResolution"] = "true"
+		form["maxImageResolution"] = strconv.Itoa(o.MaxImageResolution)
+	}
+	if o.PageRange != "" {
+		form["nativePageRanges"] = o.PageRange
+	}
+	if o.OmitBookmarks {
+		form["exportBookmarks"] = "false"
+	}
+	if o.OmitFormFields {
+		form["exportFormFields"] = "false"
+	}
+	if o.OpenPassword != "" {
+		form["userPassword"] = o.OpenPassword
+	}
+	return form, nil
+}
@@ -0,0 +1,107 @@
+package convert
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPDFOptions(t *testing.T) {
+	Convey("Test PDF Options: LibreOffice Filter Options", t, func() {
+		So(PDFOptions{}.FilterOptions(), ShouldEqual, "")
+		convertTo, err := PDFOptions{}.convertTo()
+		So(err, ShouldBeNil)
+		So(convertTo, ShouldEqual, "pdf")
+
+		opts := PDFOptions{
+			PDFA:               PDFA2b,
+			Tagged:             true,
+			ImageQuality:       80,
+			MaxImageResolution: 300,
+			PageRange:          "1-3",
+			OmitBookmarks:      true,
+			OmitFormFields:     true,
+			Watermark:          "DRAFT",
+		}
+		So(opts.FilterOptions(), ShouldEqual, `{"ExportBookmarks":{"type":"boolean","value":"false"},`+
+			`"ExportFormFields":{"type":"boolean","value":"false"},`+
+			`"MaxImageResolution":{"type":"long","value":"300"},`+
+			`"PageRange":{"type":"string","value":"1-3"},`+
+			`"Quality":{"type":"long","value":"80"},`+
+			`"ReduceImageResolution":{"type":"boolean","value":"true"},`+
+			`"SelectPdfVersion":{"type":"long","value":"2"},`+
+			`"UseTaggedPDF":{"type":"boolean","value":"true"},`+
+			`"Watermark":{"type":"string","value":"DRAFT"}}`)
+
+		opts = PDFOptions{LosslessImages: true, OpenPassword: `se"cret`}
+		So(opts.FilterOptions(), ShouldEqual, `{"UseLosslessCompression":{"type":"boolean","value":"true"}}`)
+		// the password would be on the command line of LibreOffice
+		_, err = opts.convertTo()
+		So(errors.Is(err, ErrUnsupportedPDFOptions), ShouldBeTrue)
+		_, err = LibreOffice{}.ConvertPDF(context.Background(), nil, opts)
+		So(errors.Is(err, ErrUnsupportedPDFOptions), ShouldBeTrue)
+	})
+
+	Convey("Test PDF Options: Validation", t, func() {
+		So(PDFOptions{PDFA: PDFA3b, Tagged: true}.Validate(), ShouldBeNil)
+		for _, opts := range []PDFOptions{
+			{PDFA: PDFA(7)},
+			{ImageQuality: 101},
+			{MaxImageResolution: -1},
+			{PDFA: PDFA1b, OpenPassword: "secret"},
+		} {
+			So(errors.Is(opts.Validate(), ErrInvalidPDFOptions), ShouldBeTrue)
+		}
+		_, err := LibreOffice{}.ConvertPDF(context.Background(), nil, PDFOptions{ImageQuality: 101})
+		So(errors.Is(err, ErrInvalidPDFOptions), ShouldBeTrue)
+	})
+
+	Convey("Test PDF Options: Gotenberg Form Fields", t, func() {
+		var form map[string][]string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.ParseMultipartForm(1 << 20)
+			form = r.MultipartForm.Value
+			w.Write([]byte("%PDF"))
+		}))
+		defer srv.Close()
+
+		_, err := Gotenberg(srv.URL).ConvertPDF(context.Background(), []byte("docx"), PDFOptions{
+			PDFA:           PDFA2b,
+			PageRange:      "2",
+			OmitBookmarks:  true,
+			LosslessImages: true,
+		})
+		So(err, ShouldBeNil)
+		So(form, ShouldResemble, map[string][]string{
+			"pdfa":                     {"PDF/A-2b"},
+			"nativePageRanges":         {"2"},
+			"exportBookmarks":          {"false"},
+			"losslessImageCompression": {"true"},
+		})
+		_, err = Gotenberg(srv.URL).ConvertPDF(context.Background(), []byte("docx"), PDFOptions{OpenPassword: "secret"})
+		So(err, ShouldBeNil)
+		So(form, ShouldResemble, map[string][]string{"userPassword": {"secret"}})
+
+		for _, opts := range []PDFOptions{{Watermark: "DRAFT"}, {Tagged: true}} {
+			_, err = Gotenberg(srv.URL).ConvertPDF(context.Background(), []byte("docx"), opts)
+			So(errors.Is(err, ErrUnsupportedPDFOptions), ShouldBeTrue)
+		}
+		_, err = Unoserver(srv.URL).ConvertPDF(context.Background(), []byte("docx"), PDFOptions{Tagged: true})
+		So(err, ShouldEqual, ErrUnsupportedPDFOptions)
+	})
+
+	Convey("Test PDF Options: LibreOffice Arguments", t, func() {
+		if runtime.GOOS == "windows" {
+			return
+		}
+		bin := standIn(t, "printf '%s' \"$3\" > \"$5/document.pdf\"\n")
+		pdf, err := LibreOffice{Binary: bin, TempDir: t.TempDir()}.ConvertPDF(context.Background(), []byte("docx"), PDFOptions{PDFA: PDFA1b})
+		So(err, ShouldBeNil)
+		So(string(pdf), ShouldEqual, `pdf:writer_pdf_Export:{"SelectPdfVersion":{"type":"long","value":"1"}}`)
+	})
+}
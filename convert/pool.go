@@ -0,0 +1,395 @@
+package convert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/saman3d/samdoc"
+)
+
+var (
+	ErrQueueFull  = errors.New("conversion queue is full")
+	ErrPoolClosed = errors.New("converter pool is closed")
+)
+
+// PoolOptions configure a Pool, zero values take the defaults
+type PoolOptions struct {
+	// Size is the number of LibreOffice processes, 2 by default
+	Size int
+	// MaxConversions recycles a process after that many conversions, zero
+	// never recycles them
+	MaxConversions int
+	// QueueSize is the number of conversions that may wait for a process,
+	// further ones fail with ErrQueueFull. Zero queues any number of them.
+	QueueSize int
+	// Binary is the LibreOffice executable, soffice by default
+	Binary string
+	// TempDir holds the profiles of the processes and the documents being
+	// converted, samdoc.TEMPDIR by default
+	TempDir string
+	// Timeout bounds every conversion, a process timing out is recycled
+	Timeout time.Duration
+	// HealthCheck is how long a process may stay idle before its next
+	// conversion is preceded by a conversion of an empty document, a
+	// process failing it is restarted. A minute by default, a negative
+	// duration never checks idle processes.
+	HealthCheck time.Duration
+}
+
+// restartAttempts is how many times a process failing to start is started
+// again before its conversion fails
+const restartAttempts = 3
+
+// startTimeout bounds the wait for a new process to convert its first
+// document
+var startTimeout = 2 * time.Minute
+
+// PoolStats are the metrics of a Pool
+type PoolStats struct {
+	Workers int
+	// Idle is the number of processes waiting for a conversion
+	Idle int
+	// Queued is the number of conversions waiting for a process
+	Queued      int
+	Conversions uint64
+	Failures    uint64
+	Rejected    uint64
+	// Restarts counts the processes restarted because they were recycled,
+	// exited or failed a conversion
+	Restarts uint64
+	// Wait is the total time conversions waited for a process and Busy the
+	// total time processes spent converting
+	Wait time.Duration
+	Busy time.Duration
+}
+
+// Pool converts documents with a pool of long-lived headless LibreOffice
+// processes, each with a profile of its own. Every conversion runs a short
+// lived soffice which LibreOffice hands the document to the running process
+// of its profile with, saving the start up of the office and its profile,
+// and conversions of different processes run in parallel. A process is only
+// put to use once it has converted an empty document.
+type Pool struct {
+	opts   PoolOptions
+	idle   chan *worker
+	closed chan struct{}
+
+	// mu guards the stats and the closing of the pool
+	mu      sync.Mutex
+	stats   PoolStats
+	closing bool
+}
+
+// NewPool starts the processes of a pool, Close stops them
+func NewPool(opts PoolOptions) (*Pool, error) {
+	if opts.Size <= 0 {
+		opts.Size = 2
+	}
+	if opts.Binary == "" {
+		opts.Binary = "soffice"
+	}
+	if opts.TempDir == "" {
+		opts.TempDir = samdoc.TEMPDIR
+	}
+	if opts.HealthCheck == 0 {
+		opts.HealthCheck = time.Minute
+	}
+
+	p := &Pool{
+		opts:   opts,
+		idle:   make(chan *worker, opts.Size),
+		closed: make(chan struct{}),
+	}
+	p.stats.Workers = opts.Size
+	for i := 0; i < opts.Size; i++ {
+		profile, err := os.MkdirTemp(opts.TempDir, samdoc.PREFIX+"profile-")
+		if err != nil {
+			p.Close()
+			return nil, err
+		}
+		w := &worker{lo: LibreOffice{Binary: opts.Binary, TempDir: opts.TempDir, Timeout: opts.Timeout, Profile: profile}}
+		err = w.start()
+		if err != nil {
+			w.close()
+			p.Close()
+			return nil, err
+		}
+		p.idle <- w
+	}
+	return p, nil
+}
+
+// Convert converts the document with the first idle process, it waits for
+// one as long as the context allows
+func (p *Pool) Convert(ctx context.Context, docx []byte) ([]byte, error) {
+	return p.ConvertPDF(ctx, docx, PDFOptions{})
+}
+
+// ConvertPDF is Convert exporting the PDF with the options
+func (p *Pool) ConvertPDF(ctx context.Context, docx []byte, opts PDFOptions) ([]byte, error) {
+	return p.ConvertTo(ctx, docx, PDF, Options{PDF: opts})
+}
+
+// ConvertTo is Convert converting the document to the format
+func (p *Pool) ConvertTo(ctx context.Context, docx []byte, format Format, opts Options) ([]byte, error) {
+	if _, err := opts.convertTo(format); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	w, err := p.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	wait := time.Since(start)
+
+	err = p.check(ctx, w)
+	if err != nil {
+		return nil, err
+	}
+	out, err := w.lo.ConvertTo(ctx, docx, format, opts)
+	w.conversions++
+	if err == nil {
+		w.checked = time.Now()
+	}
+
+	p.mu.Lock()
+	p.stats.Conversions++
+	p.stats.Wait += wait
+	p.stats.Busy += time.Since(start) - wait
+	if err != nil {
+		p.stats.Failures++
+	}
+	p.mu.Unlock()
+
+	// a failed conversion may leave the process hung on the document, a
+	// process failing to restart is restarted by its next conversion
+	if err != nil || p.opts.MaxConversions > 0 && w.conversions >= p.opts.MaxConversions {
+		go func() {
+			p.restart(w)
+			p.release(w)
+		}()
+		return out, err
+	}
+	p.release(w)
+	return out, err
+}
+
+// check restarts the process of the worker when it exited, or when it has
+// been idle for longer than the health check and fails to convert an empty
+// document. A caller giving up on the restart gets the error of its context
+// right away and the worker is released once it's restarted.
+func (p *Pool) check(ctx context.Context, w *worker) error {
+	if w.running() {
+		if p.opts.HealthCheck < 0 || time.Since(w.checked) < p.opts.HealthCheck {
+			return nil
+		}
+		err := w.probe(ctx)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			p.release(w)
+			return ctx.Err()
+		}
+	}
+
+	done := make(chan error)
+	go func() {
+		err := p.restart(w)
+		select {
+		case done <- err:
+		case <-ctx.Done():
+			p.release(w)
+		}
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			p.release(w)
+		}
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *Pool) acquire(ctx context.Context) (*worker, error) {
+	select {
+	case <-p.closed:
+		return nil, ErrPoolClosed
+	default:
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	if p.opts.QueueSize > 0 && p.stats.Queued >= p.opts.QueueSize && len(p.idle) == 0 {
+		p.stats.Rejected++
+		p.mu.Unlock()
+		return nil, ErrQueueFull
+	}
+	p.stats.Queued++
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		p.stats.Queued--
+		p.mu.Unlock()
+	}()
+	select {
+	case w := <-p.idle:
+		return w, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-p.closed:
+		return nil, ErrPoolClosed
+	}
+}
+
+func (p *Pool) release(w *worker) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closing {
+		w.close()
+		return
+	}
+	p.idle <- w
+}
+
+// restart replaces the process of the worker with a new one, starting it up
+// to restartAttempts times
+func (p *Pool) restart(w *worker) error {
+	p.mu.Lock()
+	p.stats.Restarts++
+	p.mu.Unlock()
+
+	var err error
+	for i := 0; i < restartAttempts; i++ {
+		w.stop()
+		err = w.start()
+		if err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// Stats returns the metrics of the pool
+func (p *Pool) Stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := p.stats
+	s.Idle = len(p.idle)
+	return s
+}
+
+// Close stops the idle processes and the busy ones once their conversions
+// are done, and removes the profiles of the stopped ones
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.closing {
+		p.closing = true
+		close(p.closed)
+	}
+	for {
+		select {
+		case w := <-p.idle:
+			w.close()
+		default:
+			return nil
+		}
+	}
+}
+
+// worker is a LibreOffice process of a pool
+type worker struct {
+	lo          LibreOffice
+	cmd         *exec.Cmd
+	exited      chan struct{}
+	conversions int
+	// checked is when the process last converted a document
+	checked time.Time
+}
+
+// start starts the process of the worker and waits until it converts an
+// empty document, a process which doesn't in time is stopped
+func (w *worker) start() error {
+	w.conversions = 0
+	w.cmd = exec.Command(w.lo.binary(), w.lo.args("--headless", "--invisible", "--nologo", "--norestore", "--nodefault", "--nolockcheck")...)
+	setProcessGroup(w.cmd)
+	err := w.cmd.Start()
+	if err != nil {
+		w.cmd = nil
+		return err
+	}
+
+	exited := make(chan struct{})
+	w.exited = exited
+	go func(cmd *exec.Cmd) {
+		cmd.Wait()
+		close(exited)
+	}(w.cmd)
+
+	// the conversions of a process still starting up aren't handed to it
+	ctx, cancel := context.WithTimeout(context.Background(), startTimeout)
+	defer cancel()
+	for {
+		err = w.probe(ctx)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-exited:
+			w.cmd = nil
+			return fmt.Errorf("%w: %s exited on start: %v", ErrConversionFailed, w.lo.binary(), err)
+		case <-ctx.Done():
+			w.stop()
+			return err
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// probe converts an empty text document with the process of the worker
+func (w *worker) probe(ctx context.Context) error {
+	_, err := w.lo.convert(ctx, "probe.txt", nil, "pdf", "probe.pdf")
+	if err == nil {
+		w.checked = time.Now()
+	}
+	return err
+}
+
+// running reports whether the process of the worker is running
+func (w *worker) running() bool {
+	if w.cmd == nil {
+		return false
+	}
+	select {
+	case <-w.exited:
+		return false
+	default:
+		return true
+	}
+}
+
+func (w *worker) stop() {
+	if w.cmd == nil {
+		return
+	}
+	killProcessGroup(w.cmd)
+	<-w.exited
+	w.cmd = nil
+}
+
+// close stops the process of the worker for good and removes its profile
+func (w *worker) close() {
+	w.stop()
+	os.RemoveAll(w.lo.Profile)
+}
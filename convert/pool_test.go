@@ -0,0 +1,266 @@
+package convert
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// poolStandIn writes a stand-in for soffice. Like LibreOffice, a conversion
+// hands the document to the running process of its profile, through a fifo,
+// and the process converts it. The process logs its profile and pid when it
+// starts and its pid with every conversion, empty documents are logged as
+// probes. Documents containing slow or fail are converted slowly or fail,
+// and a process whose profile holds a hang file stops converting.
+func poolStandIn(t *testing.T) (bin string, log string) {
+	log = filepath.Join(t.TempDir(), "log")
+	bin = standIn(t, `profile=${1#-env:UserInstallation=file://}
+fifo=$profile/fifo
+case " $* " in
+*" --convert-to "*)
+	[ -p "$fifo" ] || exit 1
+	echo "$6 $7" > "$fifo"
+	while [ ! -f "$6/status" ]; do sleep 0.01; done
+	[ "$(cat "$6/status")" = ok ] ;;
+*)
+	rm -f "$profile/hang"
+	[ -p "$fifo" ] || mkfifo "$fifo"
+	echo "start $1 $$" >> `+log+`
+	exec 3<> "$fifo"
+	while read -r dir input <&3; do
+		[ -f "$profile/hang" ] && continue
+		if [ ! -s "$input" ]; then
+			echo "probe $$" >> `+log+`
+		else
+			echo "convert $$" >> `+log+`
+		fi
+		status=ok
+		grep -q fail "$input" && status=fail
+		grep -q slow "$input" && sleep 1
+		name=$(basename "$input")
+		cp "$input" "$dir/${name%.*}.pdf"
+		echo $status > "$dir/status"
+	done ;;
+esac
+`)
+	return bin, log
+}
+
+func readLog(log string) []string {
+	b, _ := os.ReadFile(log)
+	return strings.Split(strings.TrimSpace(string(b)), "\n")
+}
+
+func countPrefix(lines []string, prefix string) int {
+	n := 0
+	for _, l := range lines {
+		if strings.HasPrefix(l, prefix) {
+			n++
+		}
+	}
+	return n
+}
+
+// pids returns the set of the pids of the lines of the log with the prefix
+func pids(lines []string, prefix string) map[string]bool {
+	set := map[string]bool{}
+	for _, l := range lines {
+		if f := strings.Fields(l); len(f) > 1 && f[0] == prefix {
+			set[f[len(f)-1]] = true
+		}
+	}
+	return set
+}
+
+func TestPool(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stand-in binary is a shell script")
+	}
+
+	Convey("Test Pool: Concurrent Conversions With A Profile Per Process", t, func() {
+		bin, log := poolStandIn(t)
+		tmp := t.TempDir()
+		p, err := NewPool(PoolOptions{Size: 2, Binary: bin, TempDir: tmp})
+		So(err, ShouldBeNil)
+
+		var wg sync.WaitGroup
+		errs := make([]error, 8)
+		for i := range errs {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				pdf, err := p.Convert(context.Background(), []byte("docx"))
+				if err == nil && string(pdf) != "docx" {
+					err = errors.New(string(pdf))
+				}
+				errs[i] = err
+			}(i)
+		}
+		wg.Wait()
+		for _, err := range errs {
+			So(err, ShouldBeNil)
+		}
+
+		lines := readLog(log)
+		profiles := map[string]bool{}
+		for _, l := range lines {
+			if f := strings.Fields(l); f[0] == "start" {
+				profiles[f[1]] = true
+			}
+		}
+		So(profiles, ShouldHaveLength, 2)
+		So(countPrefix(lines, "convert"), ShouldEqual, 8)
+		So(countPrefix(lines, "start"), ShouldEqual, 2)
+		// the processes started with the pool did every conversion
+		So(pids(lines, "convert"), ShouldResemble, pids(lines, "start"))
+
+		stats := p.Stats()
+		So(stats.Workers, ShouldEqual, 2)
+		So(stats.Idle, ShouldEqual, 2)
+		So(stats.Conversions, ShouldEqual, 8)
+		So(stats.Failures, ShouldEqual, 0)
+
+		So(p.Close(), ShouldBeNil)
+		_, err = p.Convert(context.Background(), []byte("docx"))
+		So(err, ShouldEqual, ErrPoolClosed)
+		entries, err := os.ReadDir(tmp)
+		So(err, ShouldBeNil)
+		So(entries, ShouldBeEmpty)
+	})
+
+	Convey("Test Pool: Recycling And Health Checks", t, func() {
+		bin, log := poolStandIn(t)
+		p, err := NewPool(PoolOptions{Size: 1, MaxConversions: 2, Binary: bin, TempDir: t.TempDir()})
+		So(err, ShouldBeNil)
+		defer p.Close()
+
+		for i := 0; i < 5; i++ {
+			_, err = p.Convert(context.Background(), []byte("docx"))
+			So(err, ShouldBeNil)
+		}
+		So(p.Stats().Restarts, ShouldEqual, 2)
+		So(countPrefix(readLog(log), "start"), ShouldEqual, 3)
+
+		// a process which died while idle is restarted by the next conversion
+		w := <-p.idle
+		killProcessGroup(w.cmd)
+		<-w.exited
+		p.idle <- w
+		_, err = p.Convert(context.Background(), []byte("docx"))
+		So(err, ShouldBeNil)
+		So(p.Stats().Restarts, ShouldEqual, 3)
+
+		// and so is a process whose conversion failed, in the background
+		_, err = p.Convert(context.Background(), []byte("fail"))
+		So(errors.Is(err, ErrConversionFailed), ShouldBeTrue)
+		So(p.Stats().Failures, ShouldEqual, 1)
+		waitFor(func() bool { return p.Stats().Idle == 1 })
+		So(p.Stats().Restarts, ShouldEqual, 4)
+	})
+
+	Convey("Test Pool: Idle Processes Are Probed", t, func() {
+		bin, log := poolStandIn(t)
+		p, err := NewPool(PoolOptions{Size: 1, HealthCheck: time.Nanosecond, Timeout: time.Second, Binary: bin, TempDir: t.TempDir()})
+		So(err, ShouldBeNil)
+		defer p.Close()
+
+		_, err = p.Convert(context.Background(), []byte("docx"))
+		So(err, ShouldBeNil)
+		So(p.Stats().Restarts, ShouldEqual, 0)
+
+		// a running process which stopped converting is restarted
+		w := <-p.idle
+		So(os.WriteFile(filepath.Join(w.lo.Profile, "hang"), nil, 0o600), ShouldBeNil)
+		p.idle <- w
+		pdf, err := p.Convert(context.Background(), []byte("docx"))
+		So(err, ShouldBeNil)
+		So(string(pdf), ShouldEqual, "docx")
+		So(p.Stats().Restarts, ShouldEqual, 1)
+
+		lines := readLog(log)
+		So(pids(lines, "start"), ShouldHaveLength, 2)
+		// the probes of both starts and of the first conversion, the hung
+		// process doesn't log its probe
+		So(countPrefix(lines, "probe"), ShouldEqual, 3)
+	})
+
+	Convey("Test Pool: Processes Failing To Start", t, func() {
+		bin := standIn(t, "exit 1\n")
+		_, err := NewPool(PoolOptions{Size: 1, Binary: bin, TempDir: t.TempDir()})
+		So(errors.Is(err, ErrConversionFailed), ShouldBeTrue)
+
+		// a process which can't be restarted fails the conversion
+		bin, _ = poolStandIn(t)
+		p, err := NewPool(PoolOptions{Size: 1, Binary: bin, TempDir: t.TempDir()})
+		So(err, ShouldBeNil)
+		defer p.Close()
+		w := <-p.idle
+		killProcessGroup(w.cmd)
+		<-w.exited
+		p.idle <- w
+		So(os.WriteFile(bin, []byte("#!/bin/sh\nexit 1\n"), 0o700), ShouldBeNil)
+		_, err = p.Convert(context.Background(), []byte("docx"))
+		So(errors.Is(err, ErrConversionFailed), ShouldBeTrue)
+		So(p.Stats().Idle, ShouldEqual, 1)
+
+		// a caller doesn't wait for a slow restart past its deadline
+		w = <-p.idle
+		p.idle <- w
+		So(os.WriteFile(bin, []byte("#!/bin/sh\nsleep 0.3\nexit 1\n"), 0o700), ShouldBeNil)
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		_, err = p.Convert(ctx, []byte("docx"))
+		So(errors.Is(err, context.DeadlineExceeded), ShouldBeTrue)
+		So(time.Since(start), ShouldBeLessThan, 250*time.Millisecond)
+		So(p.Stats().Idle, ShouldEqual, 0)
+		waitFor(func() bool { return p.Stats().Idle == 1 })
+		So(p.Stats().Idle, ShouldEqual, 1)
+	})
+
+	Convey("Test Pool: Queue Backpressure", t, func() {
+		bin, _ := poolStandIn(t)
+		p, err := NewPool(PoolOptions{Size: 1, QueueSize: 1, Binary: bin, TempDir: t.TempDir()})
+		So(err, ShouldBeNil)
+		defer p.Close()
+
+		done := make(chan error, 2)
+		go func() {
+			_, err := p.Convert(context.Background(), []byte("slow"))
+			done <- err
+		}()
+		waitFor(func() bool { return p.Stats().Idle == 0 })
+		go func() {
+			_, err := p.Convert(context.Background(), []byte("docx"))
+			done <- err
+		}()
+		waitFor(func() bool { return p.Stats().Queued == 1 })
+
+		_, err = p.Convert(context.Background(), []byte("docx"))
+		So(err, ShouldEqual, ErrQueueFull)
+		So(p.Stats().Rejected, ShouldEqual, 1)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		So(<-done, ShouldBeNil)
+		So(<-done, ShouldBeNil)
+		_, err = p.Convert(ctx, []byte("docx"))
+		So(err, ShouldEqual, context.Canceled)
+		So(p.Stats().Wait, ShouldBeGreaterThan, 0)
+		So(p.Stats().Busy, ShouldBeGreaterThanOrEqualTo, time.Second)
+	})
+}
+
+func waitFor(cond func() bool) {
+	for i := 0; i < 250 && !cond(); i++ {
+		time.Sleep(20 * time.Millisecond)
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !unix
+
+package convert
+
+import (
+	"os/exec"
+)
+
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// killProcessGroup kills the process of the command, its children are left
+// to exit once their pipes are closed
+func killProcessGroup(cmd *exec.Cmd) {
+	if cmd.Process != nil {
+		cmd.Process.Kill()
+	}
+}
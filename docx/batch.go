@@ -0,0 +1,175 @@
+package docx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// BatchOptions configure Template.ExecuteBatch
+type BatchOptions struct {
+	// Workers is the number of documents rendered at once, the number of
+	// CPUs by default
+	Workers int
+	// Progress is called after every document, one call at a time
+	Progress func(Progress)
+	// Exts are applied to every document
+	Exts []TemplateExecuteExtension
+}
+
+// Progress reports the document of a batch which was just handled
+type Progress struct {
+	// Index is the index of the model of the document
+	Index int
+	// Err is the error of the document, if any
+	Err error
+	// Done and Failed count the documents handled so far
+	Done   int
+	Failed int
+}
+
+// ItemError is the error of a single document of a batch
+type ItemError struct {
+	Index int
+	Err   error
+}
+
+func (e ItemError) Error() string {
+	return fmt.Sprintf("item %d: %v", e.Index, e.Err)
+}
+
+func (e ItemError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError lists the documents of a batch which failed, in order
+type BatchError struct {
+	Items []ItemError
+}
+
+func (e *BatchError) Error() string {
+	errs := make([]string, len(e.Items))
+	for i, item := range e.Items {
+		errs[i] = item.Error()
+	}
+	return fmt.Sprintf("%d documents failed: %s", len(errs), strings.Join(errs, "; "))
+}
+
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, len(e.Items))
+	for i, item := range e.Items {
+		errs[i] = item
+	}
+	return errs
+}
+
+// ExecuteBatch executes the template against every model received from the
+// channel and writes the documents to the sink until the channel is closed
+// or the context is done. Documents which fail aren't written and are
+// reported by a BatchError, the other documents of the batch are still
+// rendered.
+//
+//	models := make(chan interface{})
+//	go func() {
+//		defer close(models)
+//		for _, c := range certificates {
+//			select {
+//			case models <- c:
+//			case <-ctx.Done():
+//				return
+//			}
+//		}
+//	}()
+//	err := tmp.ExecuteBatch(ctx, models, docx.DirSink("out", nil), docx.BatchOptions{})
+func (t *Template) ExecuteBatch(ctx context.Context, models <-chan interface{}, sink Sink, opts BatchOptions) error {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	type job struct {
+		indx  int
+		model interface{}
+	}
+	jobs := make(chan job)
+	results := make(chan ItemError)
+
+	go func() {
+		defer close(jobs)
+		for indx := 0; ; indx++ {
+			select {
+			case <-ctx.Done():
+				return
+			case model, ok := <-models:
+				if !ok {
+					return
+				}
+				select {
+				case jobs <- job{indx: indx, model: model}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				results <- ItemError{Index: j.indx, Err: t.executeItem(j.indx, j.model, sink, opts.Exts)}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var berr BatchError
+	var p Progress
+	for res := range results {
+		p.Index, p.Err = res.Index, res.Err
+		p.Done++
+		if res.Err != nil {
+			p.Failed++
+			berr.Items = append(berr.Items, res)
+		}
+		if opts.Progress != nil {
+			opts.Progress(p)
+		}
+	}
+
+	var errs error
+	if len(berr.Items) != 0 {
+		sort.Slice(berr.Items, func(i, j int) bool {
+			return berr.Items[i].Index < berr.Items[j].Index
+		})
+		errs = &berr
+	}
+	return errors.Join(errs, ctx.Err())
+}
+
+// executeItem renders a single document of a batch and writes it to the sink
+// when it succeeds
+func (t *Template) executeItem(indx int, model interface{}, sink Sink, exts []TemplateExecuteExtension) error {
+	buf := new(bytes.Buffer)
+	err := t.ExecuteToWriter(model, buf, exts...)
+	if err != nil {
+		return err
+	}
+
+	w, err := sink.Create(indx, model)
+	if err != nil {
+		return err
+	}
+	_, err = buf.WriteTo(w)
+	return errors.Join(err, w.Close())
+}
@@ -0,0 +1,197 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/saman3d/samdoc/records"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// sendModels returns a channel receiving the models
+func sendModels(models ...interface{}) <-chan interface{} {
+	ch := make(chan interface{})
+	go func() {
+		defer close(ch)
+		for _, m := range models {
+			ch <- m
+		}
+	}()
+	return ch
+}
+
+func TestExecuteBatch(t *testing.T) {
+	file := newTestDocx(`<w:p><w:r><w:t>{{Name}} {{Grade}}</w:t></w:r></w:p>`, nil)
+	var models []interface{}
+	for i := 0; i < 20; i++ {
+		m := map[string]interface{}{"Name": fmt.Sprintf("student %d", i), "Grade": i}
+		if i%7 == 3 {
+			delete(m, "Grade")
+		}
+		models = append(models, m)
+	}
+
+	Convey("Test Batch: Zip Sink With Failures And Progress", t, func() {
+		tmp, err := NewTemplate(bytes.NewReader(file))
+		So(err, ShouldBeNil)
+		tmp.Strict()
+
+		buf := new(bytes.Buffer)
+		sink := NewZipSink(buf, func(indx int, model interface{}) string {
+			return model.(map[string]interface{})["Name"].(string) + ".docx"
+		})
+		var progress []Progress
+		err = tmp.ExecuteBatch(context.Background(), sendModels(models...), sink, BatchOptions{
+			Workers:  4,
+			Progress: func(p Progress) { progress = append(progress, p) },
+		})
+		So(sink.Close(), ShouldBeNil)
+
+		var berr *BatchError
+		So(errors.As(err, &berr), ShouldBeTrue)
+		So(berr.Items, ShouldHaveLength, 3)
+		for i, indx := range []int{3, 10, 17} {
+			So(berr.Items[i].Index, ShouldEqual, indx)
+		}
+		So(errors.Is(err, ErrUnresolvedPlaceholder), ShouldBeTrue)
+		So(progress, ShouldHaveLength, 20)
+		So(progress[19].Done, ShouldEqual, 20)
+		So(progress[19].Failed, ShouldEqual, 3)
+
+		zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		So(err, ShouldBeNil)
+		So(zr.File, ShouldHaveLength, 17)
+		for _, f := range zr.File {
+			if f.Name != "student 5.docx" {
+				continue
+			}
+			r, err := f.Open()
+			So(err, ShouldBeNil)
+			doc, err := io.ReadAll(r)
+			So(err, ShouldBeNil)
+			So(documentTexts(doc), ShouldResemble, []string{"student 5 5"})
+		}
+	})
+
+	Convey("Test Batch: Directory Sink", t, func() {
+		tmp, err := NewTemplate(bytes.NewReader(file))
+		So(err, ShouldBeNil)
+
+		dir := t.TempDir()
+		err = tmp.ExecuteBatch(context.Background(), sendModels(models[:5]...), DirSink(filepath.Join(dir, "certs"), nil), BatchOptions{})
+		So(err, ShouldBeNil)
+		for i := 1; i <= 5; i++ {
+			doc, err := os.ReadFile(filepath.Join(dir, "certs", fmt.Sprintf("%d.docx", i)))
+			So(err, ShouldBeNil)
+			So(documentTexts(doc), ShouldHaveLength, 1)
+		}
+
+		for _, name := range []string{"../escaped.docx", "a/../../escaped.docx", "", "."} {
+			sink := DirSink(filepath.Join(dir, "certs"), func(int, interface{}) string { return name })
+			_, err = sink.Create(0, nil)
+			So(errors.Is(err, ErrInvalidDocumentName), ShouldBeTrue)
+		}
+		_, err = os.Stat(filepath.Join(dir, "escaped.docx"))
+		So(os.IsNotExist(err), ShouldBeTrue)
+	})
+
+	Convey("Test Batch: Zip Sink Names", t, func() {
+		for _, name := range []string{"../escaped.docx", "a/../../escaped.docx", "/abs.docx", "\\abs.docx", "..\\escaped.docx", "", "."} {
+			sink := NewZipSink(new(bytes.Buffer), func(int, interface{}) string { return name })
+			_, err := sink.Create(0, nil)
+			So(errors.Is(err, ErrInvalidDocumentName), ShouldBeTrue)
+		}
+
+		tmp, err := NewTemplate(bytes.NewReader(file))
+		So(err, ShouldBeNil)
+
+		buf := new(bytes.Buffer)
+		sink := NewZipSink(buf, func(indx int, model interface{}) string {
+			return fmt.Sprintf("certs/%d.docx", indx%2)
+		})
+		err = tmp.ExecuteBatch(context.Background(), sendModels(models[:3]...), sink, BatchOptions{})
+		So(sink.Close(), ShouldBeNil)
+		So(errors.Is(err, ErrInvalidDocumentName), ShouldBeTrue)
+
+		zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		So(err, ShouldBeNil)
+		So(zr.File, ShouldHaveLength, 2)
+		So(zr.File[0].Name, ShouldNotEqual, zr.File[1].Name)
+	})
+
+	Convey("Test Batch: Cancellation", t, func() {
+		tmp, err := NewTemplate(bytes.NewReader(file))
+		So(err, ShouldBeNil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		written := 0
+		sink := SinkFunc(func(int, interface{}) (io.WriteCloser, error) {
+			written++
+			return nopCloser{io.Discard}, nil
+		})
+		err = tmp.ExecuteBatch(ctx, make(chan interface{}), sink, BatchOptions{})
+		So(errors.Is(err, context.Canceled), ShouldBeTrue)
+		So(written, ShouldEqual, 0)
+	})
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+func TestExecuteBatchFromRecords(t *testing.T) {
+	Convey("Test Batch: Mail Merge From CSV", t, func() {
+		file := newTestDocx(`<w:p><w:r><w:t>{{Name}} from {{Customer.City}}: {{Total | number}}{{if Paid}} paid{{end}}</w:t></w:r></w:p>`, nil)
+		tmp, err := NewTemplate(bytes.NewReader(file))
+		So(err, ShouldBeNil)
+
+		models := make(chan interface{})
+		errc := make(chan error, 1)
+		csv := "Name,Customer.City,Total,Paid\nSara,Tabriz,1200000,yes\nAli,Shiraz,50,\nReza,Yazd,7,FALSE\nMina,Qom,0,0\n"
+		go func() {
+			errc <- records.Send(context.Background(), records.NewCSVReader(strings.NewReader(csv)).Bools("Paid"), models)
+		}()
+
+		var docs [][]byte
+		var mu sync.Mutex
+		sink := SinkFunc(func(indx int, _ interface{}) (io.WriteCloser, error) {
+			w := &bufferCloser{done: func(b []byte) {
+				mu.Lock()
+				defer mu.Unlock()
+				docs = append(docs, b)
+			}}
+			return w, nil
+		})
+		So(tmp.ExecuteBatch(context.Background(), models, sink, BatchOptions{Workers: 1}), ShouldBeNil)
+		So(<-errc, ShouldBeNil)
+		So(docs, ShouldHaveLength, 4)
+		So(documentTexts(docs[0]), ShouldResemble, []string{"Sara from Tabriz: 1,200,000 paid"})
+		So(documentTexts(docs[1]), ShouldResemble, []string{"Ali from Shiraz: 50"})
+		So(documentTexts(docs[2]), ShouldResemble, []string{"Reza from Yazd: 7"})
+		So(documentTexts(docs[3]), ShouldResemble, []string{"Mina from Qom: 0"})
+	})
+}
+
+type bufferCloser struct {
+	bytes.Buffer
+	done func([]byte)
+}
+
+func (b *bufferCloser) Close() error {
+	b.done(b.Bytes())
+	return nil
+}
@@ -0,0 +1,549 @@
+package docx
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/saman3d/samdoc"
+	"github.com/saman3d/samdoc/xml"
+)
+
+var (
+	ErrUnbalancedBlock = errors.New("unbalanced block markers")
+	ErrBlockNeedsModel = errors.New("blocks can only be executed against a model")
+)
+
+// Blocks are placeholders that control a part of the document rather than
+// being replaced by a value:
+//
+//	{{range Items}} ... {{end}}
+//	{{range Items}} ... {{else}} rendered when Items is empty {{end}}
+//	{{if Cond}} ... {{end}}
+//	{{if not Cond}} ... {{else}} ... {{end}}
+//
+// A paragraph or a table row holding nothing but a block marker delimits the
+// paragraphs, tables and rows that follow it, a table row starting with a
+// block marker in its first paragraph and ending with the matching end marker
+// in its last one makes a block of the row itself, any other markers make a
+// block of the runs between them and must be closed in the same paragraph.
+type blockKind int
+
+const (
+	blockNone blockKind = iota
+	blockRange
+	blockIf
+	blockElse
+	blockEnd
+)
+
+type blockMarker struct {
+	Kind blockKind
+	Expr string
+	Not  bool
+}
+
+func parseBlockMarker(text string) blockMarker {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return blockMarker{}
+	}
+	switch fields[0] {
+	case "range":
+		if len(fields) == 2 {
+			return blockMarker{Kind: blockRange, Expr: fields[1]}
+		}
+	case "if":
+		if len(fields) == 2 {
+			return blockMarker{Kind: blockIf, Expr: fields[1]}
+		}
+		if len(fields) == 3 && fields[1] == "not" {
+			return blockMarker{Kind: blockIf, Expr: fields[2], Not: true}
+		}
+	case "else":
+		if len(fields) == 1 {
+			return blockMarker{Kind: blockElse}
+		}
+	case "end":
+		if len(fields) == 1 {
+			return blockMarker{Kind: blockEnd}
+		}
+	}
+	return blockMarker{}
+}
+
+func (m blockMarker) opens() bool {
+	return m.Kind == blockRange || m.Kind == blockIf
+}
+
+// closingMarker returns the index of the marker closing the block opened at
+// indx and the index of its else marker, or -1 when it has none
+func closingMarker(markers []blockMarker, indx int) (int, int, error) {
+	depth := 0
+	els := -1
+	for i := indx; i < len(markers); i++ {
+		switch {
+		case markers[i].opens():
+			depth++
+		case markers[i].Kind == blockElse && depth == 1:
+			if els != -1 {
+				return -1, -1, ErrUnbalancedBlock
+			}
+			els = i
+		case markers[i].Kind == blockEnd:
+			depth--
+			if depth == 0 {
+				return i, els, nil
+			}
+		}
+	}
+	return -1, -1, ErrUnbalancedBlock
+}
+
+// branches splits the items between a block's opening and end markers into
+// its body and else branch
+func branches[T any](items []T, indx, end, els int) ([]T, []T) {
+	if els == -1 {
+		return items[indx+1 : end], nil
+	}
+	return items[indx+1 : els], items[els+1 : end]
+}
+
+// options configure how a document is executed
+type options struct {
+	funcs  samdoc.FuncMap
+	locale *samdoc.Locale
+	strct  []samdoc.StructureOption
+	strict bool
+	delims Delims
+	// delimsErr fails executions when the delimiters set are invalid
+	delimsErr error
+	// controls turns unresolved placeholders into content controls showing
+	// the hint
+	controls bool
+	hint     string
+	// fallbacks resolve the placeholders the model can't
+	fallbacks []ReplacerFunc
+}
+
+// execution is the state of a single execution of a document shared by all
+// of its scopes
+type execution struct {
+	part       string
+	unresolved []UnresolvedPlaceholder
+}
+
+// unresolve records the placeholder as unresolved, once however many times
+// it's repeated by blocks
+func (run *execution) unresolve(ph UnresolvedPlaceholder) {
+	for _, prev := range run.unresolved {
+		if prev == ph {
+			return
+		}
+	}
+	run.unresolved = append(run.unresolved, ph)
+}
+
+// scope is the data a part of the document is executed against
+type scope struct {
+	strct *samdoc.Structure
+	opts  *options
+	repf  ReplacerFunc
+	run   *execution
+}
+
+// newScope creates the scope of an execution against the model, a pointer to
+// struct, a map or a ReplacerFunc, which can't execute range blocks
+func newScope(model interface{}, opts *options) (*scope, error) {
+	switch m := model.(type) {
+	case nil:
+		return &scope{opts: opts, repf: opts.resolver(NilReplacerFunc), run: new(execution)}, nil
+	case ReplacerFunc:
+		return &scope{opts: opts, repf: opts.resolver(opts.localize(m)), run: new(execution)}, nil
+	case func(string) (string, bool):
+		return &scope{opts: opts, repf: opts.resolver(opts.localize(m)), run: new(execution)}, nil
+	}
+
+	strct, err := samdoc.NewStructure(model, opts.strct...)
+	if err != nil {
+		return nil, err
+	}
+	return newStructureScope(strct, opts, new(execution)), nil
+}
+
+func newStructureScope(strct *samdoc.Structure, opts *options, run *execution) *scope {
+	return &scope{strct: strct, opts: opts, repf: opts.resolver(newStructureReplacerFunc(strct, opts)), run: run}
+}
+
+// each calls fn with the scope of every repetition of the block opened by m,
+// or once with els set when the else branch of the block is to be rendered
+func (sc *scope) each(m blockMarker, fn func(isc *scope, els bool) error) error {
+	switch m.Kind {
+	case blockRange:
+		if sc.strct == nil {
+			return ErrBlockNeedsModel
+		}
+		items, err := sc.strct.Items(samdoc.NewFieldQuery(m.Expr))
+		if err != nil {
+			return fmt.Errorf("range %s: %w", m.Expr, err)
+		}
+		if len(items) == 0 {
+			return fn(sc, true)
+		}
+		for _, item := range items {
+			err = fn(newStructureScope(item, sc.opts, sc.run), false)
+			if err != nil {
+				return err
+			}
+		}
+	case blockIf:
+		ok, err := sc.truth(m.Expr)
+		if err != nil {
+			return fmt.Errorf("if %s: %w", m.Expr, err)
+		}
+		return fn(sc, ok == m.Not)
+	}
+	return nil
+}
+
+// truth evaluates the condition of an if block, without a model the
+// placeholder is true when it's replaced by anything but "", "0" or "false"
+func (sc *scope) truth(expr string) (bool, error) {
+	if sc.strct != nil {
+		return sc.strct.Truth(samdoc.NewFieldQuery(expr))
+	}
+	val, ok := sc.repf(expr)
+	return ok && val != "" && val != "0" && val != "false", nil
+}
+
+// replace returns the chars the placeholder segment is replaced with, the
+// inserted value takes the formatting of the placeholder's first char
+func (sc *scope) replace(seg Segment) []*Char {
+	val, ok := sc.repf(seg.Text)
+	if !ok {
+		if sc.run != nil {
+			sc.run.unresolve(UnresolvedPlaceholder{Part: sc.run.part, Text: seg.Text})
+		}
+		if sc.opts.controls {
+			return []*Char{sc.contentControl(seg)}
+		}
+		return seg.Chars
+	}
+
+	format := seg.Chars[utf8.RuneCountInString(sc.opts.delims.start())]
+	val = html.EscapeString(val)
+	chars := make([]*Char, 0, len(val))
+	for _, r := range val {
+		chars = append(chars, &Char{
+			Rune: r,
+			T:    format.T,
+			R:    format.R,
+			P:    format.P,
+		})
+	}
+	return chars
+}
+
+// walk executes blocks and placeholders of all the children of the element
+func (p *Processor) walk(start *xml.UniversalElement, sc *scope) error {
+	children, err := p.expand(start.Children, sc)
+	if err != nil {
+		return err
+	}
+	start.Children = children
+	return nil
+}
+
+// expand executes a list of sibling elements and returns the elements that
+// replace them
+func (p *Processor) expand(elements []*xml.UniversalElement, sc *scope) ([]*xml.UniversalElement, error) {
+	markers := make([]blockMarker, len(elements))
+	for i, e := range elements {
+		markers[i] = elementMarker(e, sc.opts.delims)
+	}
+
+	var res = make([]*xml.UniversalElement, 0, len(elements))
+	for i := 0; i < len(elements); i++ {
+		m := markers[i]
+		if m.Kind == blockNone {
+			es, err := p.executeElement(elements[i], sc)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, es...)
+			continue
+		}
+		if !m.opens() {
+			return nil, ErrUnbalancedBlock
+		}
+
+		end, els, err := closingMarker(markers, i)
+		if err != nil {
+			return nil, err
+		}
+		body, elseBody := branches(elements, i, end, els)
+		err = sc.each(m, func(isc *scope, isElse bool) error {
+			if isElse {
+				body = elseBody
+			}
+			es, err := p.expand(cloneElements(body), isc)
+			res = append(res, es...)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		i = end
+	}
+	return res, nil
+}
+
+func (p *Processor) executeElement(e *xml.UniversalElement, sc *scope) ([]*xml.UniversalElement, error) {
+	switch e.XMLName {
+	case "w:p":
+		return []*xml.UniversalElement{e}, p.executeParagraph(e, sc)
+	case "w:tr":
+		if m, ok := rowBlock(e, sc.opts.delims); ok {
+			var res []*xml.UniversalElement
+			err := sc.each(m, func(isc *scope, isElse bool) error {
+				if isElse {
+					return nil
+				}
+				row := e.Clone()
+				res = append(res, row)
+				return p.walk(row, isc)
+			})
+			return res, err
+		}
+	}
+	return []*xml.UniversalElement{e}, p.walk(e, sc)
+}
+
+func (p *Processor) executeParagraph(para *xml.UniversalElement, sc *scope) error {
+	segs := paragraphSegments(para, sc.opts.delims)
+	if !hasPlaceholder(segs) {
+		return nil
+	}
+
+	chars, err := p.render(segs, sc)
+	if err != nil {
+		return err
+	}
+	setParagraphChars(para, chars)
+	return nil
+}
+
+// render executes the segments of a paragraph and returns the resulting chars
+func (p *Processor) render(segs []Segment, sc *scope) ([]*Char, error) {
+	markers := make([]blockMarker, len(segs))
+	for i, seg := range segs {
+		if seg.Placeholder {
+			markers[i] = parseBlockMarker(seg.Text)
+		}
+	}
+
+	var chars []*Char
+	for i := 0; i < len(segs); i++ {
+		m := markers[i]
+		switch {
+		case !segs[i].Placeholder:
+			chars = append(chars, segs[i].Chars...)
+		case m.Kind == blockNone:
+			chars = append(chars, sc.replace(segs[i])...)
+		case !m.opens():
+			return nil, ErrUnbalancedBlock
+		default:
+			end, els, err := closingMarker(markers, i)
+			if err != nil {
+				return nil, err
+			}
+			body, elseBody := branches(segs, i, end, els)
+			err = sc.each(m, func(isc *scope, isElse bool) error {
+				if isElse {
+					body = elseBody
+				}
+				cs, err := p.render(cloneSegments(body), isc)
+				chars = append(chars, cs...)
+				return err
+			})
+			if err != nil {
+				return nil, err
+			}
+			i = end
+		}
+	}
+	return chars, nil
+}
+
+// elementMarker returns the block marker a paragraph or a table row consists
+// of, or a marker of kind blockNone
+func elementMarker(e *xml.UniversalElement, d Delims) blockMarker {
+	if e.XMLName != "w:p" && e.XMLName != "w:tr" {
+		return blockMarker{}
+	}
+
+	var marker *blockMarker
+	for _, para := range paragraphs(e) {
+		for _, seg := range paragraphSegments(para, d) {
+			if !seg.Placeholder {
+				if !isBlank(seg.Chars) {
+					return blockMarker{}
+				}
+				continue
+			}
+			m := parseBlockMarker(seg.Text)
+			if m.Kind == blockNone || marker != nil {
+				return blockMarker{}
+			}
+			marker = &m
+		}
+	}
+	if marker == nil {
+		return blockMarker{}
+	}
+	return *marker
+}
+
+// rowBlock reports whether the first paragraph of the row starts with a block
+// marker which is closed at the end of its last paragraph, the markers are
+// removed from the row when it does
+func rowBlock(row *xml.UniversalElement, d Delims) (blockMarker, bool) {
+	var paras []*xml.UniversalElement
+	var segs, trimmed [][]Segment
+	for _, para := range paragraphs(row) {
+		ss := paragraphSegments(para, d)
+		if ts := trimSegments(ss); len(ts) != 0 {
+			paras = append(paras, para)
+			segs = append(segs, ss)
+			trimmed = append(trimmed, ts)
+		}
+	}
+	if len(paras) < 2 {
+		return blockMarker{}, false
+	}
+
+	first, last := trimmed[0][0], trimmed[len(trimmed)-1][len(trimmed[len(trimmed)-1])-1]
+	if !first.Placeholder || !last.Placeholder {
+		return blockMarker{}, false
+	}
+	m := parseBlockMarker(first.Text)
+	if !m.opens() {
+		return blockMarker{}, false
+	}
+
+	var markers []blockMarker
+	for _, ss := range trimmed {
+		for _, seg := range ss {
+			if seg.Placeholder {
+				markers = append(markers, parseBlockMarker(seg.Text))
+			}
+		}
+	}
+	end, els, err := closingMarker(markers, 0)
+	if err != nil || els != -1 || end != len(markers)-1 {
+		return blockMarker{}, false
+	}
+
+	setParagraphChars(paras[0], segmentsToChars(segs[0], first))
+	setParagraphChars(paras[len(paras)-1], segmentsToChars(segs[len(segs)-1], last))
+	return m, true
+}
+
+// paragraphs returns all the paragraphs within the element, the element
+// itself if it's a paragraph
+func paragraphs(e *xml.UniversalElement) []*xml.UniversalElement {
+	if e.XMLName == "w:p" {
+		return []*xml.UniversalElement{e}
+	}
+	var ps []*xml.UniversalElement
+	for _, c := range e.Children {
+		ps = append(ps, paragraphs(c)...)
+	}
+	return ps
+}
+
+func paragraphSegments(para *xml.UniversalElement, d Delims) []Segment {
+	list := &CharList{Delims: d}
+	list.LoadFromParagraph(para)
+	return list.Segments()
+}
+
+// setParagraphChars rebuilds the runs of the paragraph from the chars
+func setParagraphChars(para *xml.UniversalElement, chars []*Char) {
+	var children []*xml.UniversalElement
+	if pPr := para.GetElementByName("w:pPr"); pPr != nil {
+		children = append(children, pPr)
+	}
+	if len(chars) != 0 {
+		list := new(CharList)
+		for _, c := range chars {
+			list.Insert(c)
+		}
+		children = list.ToParagraphList()[0].Children
+	}
+	para.Children = children
+}
+
+func hasPlaceholder(segs []Segment) bool {
+	for _, seg := range segs {
+		if seg.Placeholder {
+			return true
+		}
+	}
+	return false
+}
+
+// trimSegments drops the leading and trailing blank text segments
+func trimSegments(segs []Segment) []Segment {
+	for len(segs) != 0 && !segs[0].Placeholder && isBlank(segs[0].Chars) {
+		segs = segs[1:]
+	}
+	for len(segs) != 0 && !segs[len(segs)-1].Placeholder && isBlank(segs[len(segs)-1].Chars) {
+		segs = segs[:len(segs)-1]
+	}
+	return segs
+}
+
+// segmentsToChars returns the chars of the segments except the ones of the
+// segment matching the given one
+func segmentsToChars(segs []Segment, except Segment) []*Char {
+	var chars []*Char
+	for _, seg := range segs {
+		if seg.Placeholder && seg.Chars[0] == except.Chars[0] {
+			continue
+		}
+		chars = append(chars, seg.Chars...)
+	}
+	return chars
+}
+
+func isBlank(chars []*Char) bool {
+	return strings.TrimSpace(charsToString(chars)) == ""
+}
+
+func cloneElements(es []*xml.UniversalElement) []*xml.UniversalElement {
+	clones := make([]*xml.UniversalElement, len(es))
+	for i, e := range es {
+		clones[i] = e.Clone()
+	}
+	return clones
+}
+
+// cloneSegments copies the segments, runs without text are cloned so every
+// repetition owns its elements
+func cloneSegments(segs []Segment) []Segment {
+	clones := make([]Segment, len(segs))
+	for i, seg := range segs {
+		clones[i] = seg
+		clones[i].Chars = make([]*Char, len(seg.Chars))
+		for j, c := range seg.Chars {
+			if c.Rune == 0 {
+				c = &Char{R: c.R.Clone(), P: c.P}
+			}
+			clones[i].Chars[j] = c
+		}
+	}
+	return clones
+}
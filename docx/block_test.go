@@ -0,0 +1,230 @@
+package docx
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/saman3d/samdoc/xml"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func newTestParagraph(texts ...string) *xml.UniversalElement {
+	p := &xml.UniversalElement{
+		XMLName:  "w:p",
+		Children: []*xml.UniversalElement{{XMLName: "w:pPr"}},
+	}
+	for _, t := range texts {
+		p.Children = append(p.Children, &xml.UniversalElement{
+			XMLName: "w:r",
+			Children: []*xml.UniversalElement{
+				{XMLName: "w:rPr"},
+				{XMLName: "w:t", Data: t},
+			},
+		})
+	}
+	return p
+}
+
+func newTestRow(cells ...string) *xml.UniversalElement {
+	tr := &xml.UniversalElement{XMLName: "w:tr"}
+	for _, c := range cells {
+		tr.Children = append(tr.Children, &xml.UniversalElement{
+			XMLName:  "w:tc",
+			Children: []*xml.UniversalElement{newTestParagraph(c)},
+		})
+	}
+	return tr
+}
+
+func newTestBody(children ...*xml.UniversalElement) *xml.UniversalElement {
+	return &xml.UniversalElement{
+		XMLName: "w:document",
+		Children: []*xml.UniversalElement{
+			{XMLName: "w:body", Children: children},
+		},
+	}
+}
+
+// texts returns the text of every paragraph in the element
+func texts(e *xml.UniversalElement) []string {
+	var ts []string
+	for _, p := range paragraphs(e) {
+		var b strings.Builder
+		for _, r := range p.Children {
+			if t := r.GetElementByName("w:t"); r.XMLName == "w:r" && t != nil {
+				b.WriteString(t.Data)
+			}
+		}
+		ts = append(ts, b.String())
+	}
+	return ts
+}
+
+type invoiceItem struct {
+	Name  string
+	Price int
+}
+
+type invoice struct {
+	Customer string
+	Paid     bool
+	Items    []invoiceItem
+	Tags     []string
+	Notes    []string
+}
+
+var testInvoice = &invoice{
+	Customer: "saman",
+	Items: []invoiceItem{
+		{Name: "pen", Price: 10},
+		{Name: "book", Price: 25},
+	},
+	Tags: []string{"a", "b", "c"},
+}
+
+func executeTestBody(doc *xml.UniversalElement, model interface{}) error {
+	sc, err := newScope(model, new(options))
+	if err != nil {
+		return err
+	}
+	p := &Processor{Document: doc}
+	_, err = p.execute(sc)
+	return err
+}
+
+func TestRangeBlock(t *testing.T) {
+	Convey("Test Range: Table Rows Between Marker Rows", t, func() {
+		doc := newTestBody(&xml.UniversalElement{
+			XMLName: "w:tbl",
+			Children: []*xml.UniversalElement{
+				newTestRow("Name", "Price"),
+				newTestRow("{{range Items}}", ""),
+				newTestRow("{{Name}}", "{{Price}}"),
+				newTestRow("{{end}}", ""),
+				newTestRow("Total", "35"),
+			},
+		})
+		err := executeTestBody(doc, testInvoice)
+		So(err, ShouldBeNil)
+		So(texts(doc), ShouldResemble, []string{"Name", "Price", "pen", "10", "book", "25", "Total", "35"})
+	})
+
+	Convey("Test Range: Row Delimited By Its Own Cells", t, func() {
+		doc := newTestBody(&xml.UniversalElement{
+			XMLName: "w:tbl",
+			Children: []*xml.UniversalElement{
+				newTestRow("{{range Items}}{{Name}}", "{{Price}} for {{Customer}}{{end}}"),
+			},
+		})
+		err := executeTestBody(doc, testInvoice)
+		So(err, ShouldBeNil)
+		So(texts(doc), ShouldResemble, []string{"pen", "10 for saman", "book", "25 for saman"})
+	})
+
+	Convey("Test Range: Paragraphs With Markers Split Across Runs", t, func() {
+		doc := newTestBody(
+			newTestParagraph("{{ran", "ge Items}", "}"),
+			newTestParagraph("- ", "{{Na", "me}}"),
+			newTestParagraph("{{e", "nd}}"),
+			newTestParagraph("bye"),
+		)
+		err := executeTestBody(doc, testInvoice)
+		So(err, ShouldBeNil)
+		So(texts(doc), ShouldResemble, []string{"- pen", "- book", "bye"})
+	})
+
+	Convey("Test Range: Inline Runs And Nested Blocks", t, func() {
+		doc := newTestBody(
+			newTestParagraph("tags: {{range Tags}}[{{.}}]{{end}}!"),
+			newTestParagraph("{{range Items}}"),
+			newTestParagraph("{{Name}}: {{range Tags}}{{.}}{{end}}"),
+			newTestParagraph("{{end}}"),
+		)
+		err := executeTestBody(doc, testInvoice)
+		So(err, ShouldBeNil)
+		So(texts(doc), ShouldResemble, []string{"tags: [a][b][c]!", "pen: abc", "book: abc"})
+	})
+
+	Convey("Test Range: Decoded JSON Model", t, func() {
+		var model map[string]interface{}
+		err := json.Unmarshal([]byte(`{"customer": "saman", "phones": ["0912", "0935"]}`), &model)
+		So(err, ShouldBeNil)
+
+		doc := newTestBody(newTestParagraph("{{customer}}: {{range phones}}{{.}} {{end}}- {{phones.1}}"))
+		err = executeTestBody(doc, model)
+		So(err, ShouldBeNil)
+		So(texts(doc), ShouldResemble, []string{"saman: 0912 0935 - 0935"})
+	})
+
+	Convey("Test Range: Unbalanced Markers", t, func() {
+		doc := newTestBody(newTestParagraph("{{range Items}}"), newTestParagraph("{{Name}}"))
+		So(executeTestBody(doc, testInvoice), ShouldEqual, ErrUnbalancedBlock)
+
+		doc = newTestBody(newTestParagraph("{{Name}}{{end}}"))
+		So(executeTestBody(doc, testInvoice), ShouldEqual, ErrUnbalancedBlock)
+
+		doc = newTestBody(newTestParagraph("{{range Items}}"), newTestParagraph("{{end}}"))
+		So(executeTestBody(doc, nil), ShouldEqual, ErrBlockNeedsModel)
+	})
+}
+
+func TestIfBlock(t *testing.T) {
+	Convey("Test If: Paragraphs And Tables", t, func() {
+		doc := newTestBody(
+			newTestParagraph("{{if Paid}}"),
+			newTestParagraph("paid"),
+			&xml.UniversalElement{XMLName: "w:tbl", Children: []*xml.UniversalElement{newTestRow("receipt")}},
+			newTestParagraph("{{else}}"),
+			newTestParagraph("please pay"),
+			newTestParagraph("{{end}}"),
+			newTestParagraph("{{if not Paid}}"),
+			newTestParagraph("due: ", "{{Customer}}"),
+			newTestParagraph("{{end}}"),
+		)
+		err := executeTestBody(doc, testInvoice)
+		So(err, ShouldBeNil)
+		So(texts(doc), ShouldResemble, []string{"please pay", "due: saman"})
+	})
+
+	Convey("Test If: Table Rows", t, func() {
+		doc := newTestBody(&xml.UniversalElement{
+			XMLName: "w:tbl",
+			Children: []*xml.UniversalElement{
+				newTestRow("{{if Paid}}paid", "yes{{end}}"),
+				newTestRow("{{if Items}}items", "{{range Items}}{{Name}} {{end}}{{end}}"),
+				newTestRow("{{if Notes}}"),
+				newTestRow("notes"),
+				newTestRow("{{end}}"),
+			},
+		})
+		err := executeTestBody(doc, testInvoice)
+		So(err, ShouldBeNil)
+		So(texts(doc), ShouldResemble, []string{"items", "pen book "})
+	})
+
+	Convey("Test If: Inline Runs Split Across Runs", t, func() {
+		doc := newTestBody(
+			newTestParagraph("status: {{i", "f Paid}}paid{{el", "se}}unpaid{{end}}", " {{range Notes}}{{.}}{{else}}no notes{{end}}"),
+		)
+		err := executeTestBody(doc, testInvoice)
+		So(err, ShouldBeNil)
+		So(texts(doc), ShouldResemble, []string{"status: unpaid no notes"})
+	})
+
+	Convey("Test If: Without A Model", t, func() {
+		doc := newTestBody(newTestParagraph("{{if Name}}name: {{Name}}{{else}}anonymous{{end}}"))
+		p := &Processor{Document: doc}
+		_, err := p.Replace(func(s string) (string, bool) { return "saman", s == "Name" })
+		So(err, ShouldBeNil)
+		So(texts(doc), ShouldResemble, []string{"name: saman"})
+	})
+
+	Convey("Test If: Unbalanced Else", t, func() {
+		doc := newTestBody(newTestParagraph("{{if Paid}}a{{else}}b{{else}}c{{end}}"))
+		So(executeTestBody(doc, testInvoice), ShouldEqual, ErrUnbalancedBlock)
+
+		doc = newTestBody(newTestParagraph("{{else}}"))
+		So(executeTestBody(doc, testInvoice), ShouldEqual, ErrUnbalancedBlock)
+	})
+}
@@ -2,18 +2,60 @@ package docx
 
 import (
 	"errors"
+	"fmt"
+	"html"
 	"io"
-	"strings"
+	"unicode/utf8"
 
 	"github.com/saman3d/samdoc"
 	"github.com/saman3d/samdoc/xml"
 )
 
+// StartPlace and EndPlace are the default delimiters of placeholders
 var (
 	StartPlace = "{{"
 	EndPlace   = "}}"
 )
 
+var ErrInvalidDelims = errors.New("invalid placeholder delimiters")
+
+// Delims are the delimiters of placeholders, any non empty strings such as
+// "${" and "}" or "[[" and "]]", empty ones fall back to StartPlace and
+// EndPlace
+type Delims struct {
+	Start string
+	End   string
+}
+
+// validate rejects delimiters containing the characters XML escapes, which
+// never match the escaped text of documents. Delimiters may be quotes of
+// formatter arguments, such as "«" and "»", a placeholder ends at its first
+// end delimiter so its arguments are quoted with the other quotes.
+func (d Delims) validate() error {
+	for _, delim := range []string{d.Start, d.End} {
+		for _, r := range delim {
+			if r == '<' || r == '>' || r == '&' {
+				return fmt.Errorf("%w: %q in %q", ErrInvalidDelims, r, delim)
+			}
+		}
+	}
+	return nil
+}
+
+func (d Delims) start() string {
+	if d.Start == "" {
+		return StartPlace
+	}
+	return d.Start
+}
+
+func (d Delims) end() string {
+	if d.End == "" {
+		return EndPlace
+	}
+	return d.End
+}
+
 type Char struct {
 	Rune rune
 	T    *xml.UniversalElement
@@ -31,6 +73,8 @@ type CharList struct {
 	Head  *CharNode
 	Tail  *CharNode
 	First *CharNode
+	// Delims are the delimiters Replace and Segments look for
+	Delims Delims
 }
 
 type ReplacerFunc func(placeholder string) (string, bool)
@@ -39,60 +83,89 @@ func NilReplacerFunc(_ string) (string, bool) {
 	return "", false
 }
 
+// NewStructReplacerFunc creates a ReplacerFunc resolving placeholders against
+// the model, a placeholder may pass the value through the builtin formatters:
+//
+//	{{Born | date "2006-01-02"}}
+//
+// Template.StructReplacerFunc uses the formatters and locale of a template.
 func NewStructReplacerFunc(model interface{}) (ReplacerFunc, error) {
+	return newStructReplacerFunc(model, new(options))
+}
+
+func newStructReplacerFunc(model interface{}, opts *options) (ReplacerFunc, error) {
 	if model == nil {
 		return NilReplacerFunc, nil
 	}
 
-	strct, err := samdoc.NewStructure(model)
+	strct, err := samdoc.NewStructure(model, opts.strct...)
 	if err != nil {
 		return nil, err
 	}
+	return newStructureReplacerFunc(strct, opts), nil
+}
+
+func newStructureReplacerFunc(strct *samdoc.Structure, opts *options) ReplacerFunc {
 	return func(f string) (string, bool) {
-		spl := strings.Split(strings.TrimSpace(f), ".")
+		pl, err := samdoc.ParsePipeline(f)
+		if err != nil {
+			return "", false
+		}
 
-		val, err := strct.Get(samdoc.FieldQuery(spl))
+		val, err := strct.Evaluate(pl, opts.funcs)
 		if err != nil {
 			return "", false
 		}
 
-		return val, true
-	}, nil
+		return opts.locale.Sprint(val), true
+	}
+}
+
+// NewLocaleReplacerFunc transliterates the digits of the values f replaces
+// placeholders with to the digits of the locale
+func NewLocaleReplacerFunc(f ReplacerFunc, l *samdoc.Locale) ReplacerFunc {
+	return func(placeholder string) (string, bool) {
+		val, ok := f(placeholder)
+		if !ok {
+			return "", false
+		}
+		return l.Transliterate(val), true
+	}
 }
 
 func (l *CharList) LoadFromElement(con *xml.UniversalElement) {
-	nump := 0
 	for _, p := range con.Children {
-		if p.XMLName == "w:p" {
-			for _, r := range p.Children {
-				if r.XMLName == "w:r" || r.XMLName == "w:hyperlink" {
-					nump++
-					t := r.GetElementByName("w:t")
-					if t == nil {
-						char := &Char{
-							Rune: 0,
-							T:    nil,
-							R:    r,
-							P:    p,
-						}
-						l.Insert(char)
-					} else {
-						for _, ch := range t.Data {
-							char := &Char{
-								Rune: ch,
-								T:    t,
-								R:    r,
-								P:    p,
-							}
-							l.Insert(char)
-						}
-					}
+		if p.XMLName != "w:p" {
+			break
+		}
+		l.LoadFromParagraph(p)
+	}
+}
 
+// LoadFromParagraph appends the chars of every run of the paragraph to the list
+func (l *CharList) LoadFromParagraph(p *xml.UniversalElement) {
+	for _, r := range p.Children {
+		if r.XMLName == "w:r" || r.XMLName == "w:hyperlink" {
+			t := r.GetElementByName("w:t")
+			if t == nil {
+				char := &Char{
+					Rune: 0,
+					T:    nil,
+					R:    r,
+					P:    p,
+				}
+				l.Insert(char)
+			} else {
+				for _, ch := range t.Data {
+					char := &Char{
+						Rune: ch,
+						T:    t,
+						R:    r,
+						P:    p,
+					}
+					l.Insert(char)
 				}
 			}
-
-		} else {
-			break
 		}
 	}
 }
@@ -201,23 +274,37 @@ func (l *CharList) LookAhead(t int) string {
 }
 
 func (l *CharList) LookAheadTill(delim string) (string, error) {
-	b := ""
+	runes := []rune(delim)
+	var b []rune
 	for c := l.Head.Next; c != nil; c = c.Next {
-		if chs := c; chs.Char.Rune == rune(delim[0]) {
-			for i, ch := range delim[1:] {
-				chs = chs.Next
-				if chs.Char.Rune != rune(ch) {
-					break
-				} else if i == len(delim)-2 {
-					return b, nil
-				}
-			}
+		if matchNodes(c, runes) {
+			return string(b), nil
 		}
-		b += string(c.Char.Rune)
+		b = append(b, c.Char.Rune)
 	}
 	return "", errors.New("didn't find a match")
 }
 
+// matchNodes reports whether the chars starting at the node are the runes
+func matchNodes(c *CharNode, runes []rune) bool {
+	for _, r := range runes {
+		if c == nil || c.Char.Rune != r {
+			return false
+		}
+		c = c.Next
+	}
+	return true
+}
+
+// Chars returns all the chars of the list in order
+func (l *CharList) Chars() []*Char {
+	var chars []*Char
+	for c := l.First; c != nil; c = c.Next {
+		chars = append(chars, c.Char)
+	}
+	return chars
+}
+
 func (l *CharList) String() string {
 	b := ""
 	l.GoToFirst()
@@ -242,7 +329,7 @@ func (l *CharList) Seek(t int) {
 
 func (l *CharList) SeekTill(delim string) error {
 	for ; l.Head.Next != nil; l.Next() {
-		if l.LookAhead(len(delim)) == delim {
+		if l.LookAhead(utf8.RuneCountInString(delim)) == delim {
 			return nil
 		}
 	}
@@ -264,23 +351,26 @@ func (l *CharList) Remove(t int) error {
 }
 
 func (l *CharList) Replace(rf ReplacerFunc) error {
+	start, end := l.Delims.start(), l.Delims.end()
+	ns, ne := utf8.RuneCountInString(start), utf8.RuneCountInString(end)
 	l.GoToFirst()
 	var err error
 	var res string
-	for err = l.SeekTill(StartPlace); err == nil; l.SeekTill(StartPlace) {
-		l.Seek(2)
-		res, err = l.LookAheadTill(EndPlace)
+	for err = l.SeekTill(start); err == nil; l.SeekTill(start) {
+		l.Seek(ns)
+		res, err = l.LookAheadTill(end)
 		if err != nil {
 			continue
 		}
+		n := utf8.RuneCountInString(res)
 		repstring, ok := rf(res)
 		if !ok {
-			l.Seek(2 + len(res))
+			l.Seek(ne + n)
 			continue
 		}
 		char := l.Next()
-		l.Seek(-3)
-		l.Remove(4 + len(res))
+		l.Seek(-1 - ns)
+		l.Remove(ns + n + ne)
 		l.Next()
 		for _, rchar := range repstring {
 			nchar := &Char{
@@ -295,6 +385,69 @@ func (l *CharList) Replace(rf ReplacerFunc) error {
 	return nil
 }
 
+// Segment is a sequence of consecutive chars of a CharList, either plain
+// text or a placeholder along with its delimiters
+type Segment struct {
+	Chars       []*Char
+	Text        string
+	Placeholder bool
+}
+
+// Segments splits the list into plain text and placeholder segments, Text of
+// a placeholder segment holds its unescaped content without the delimiters
+func (l *CharList) Segments() []Segment {
+	var segs []Segment
+	start, end := []rune(l.Delims.start()), []rune(l.Delims.end())
+	chars := l.Chars()
+	text := 0
+	for i := 0; i < len(chars); i++ {
+		if !matchRunes(chars, i, start) {
+			continue
+		}
+		j := i + len(start)
+		for j < len(chars) && chars[j].Rune != 0 && !matchRunes(chars, j, end) {
+			j++
+		}
+		if j == len(chars) || chars[j].Rune == 0 {
+			continue
+		}
+		if text < i {
+			segs = append(segs, Segment{Chars: chars[text:i]})
+		}
+		segs = append(segs, Segment{
+			Chars:       chars[i : j+len(end)],
+			Text:        html.UnescapeString(charsToString(chars[i+len(start) : j])),
+			Placeholder: true,
+		})
+		text = j + len(end)
+		i = text - 1
+	}
+	if text < len(chars) {
+		segs = append(segs, Segment{Chars: chars[text:]})
+	}
+	return segs
+}
+
+func matchRunes(chars []*Char, indx int, runes []rune) bool {
+	if len(chars)-indx < len(runes) {
+		return false
+	}
+	for i, r := range runes {
+		if chars[indx+i].Rune != r {
+			return false
+		}
+	}
+	return true
+}
+
+func charsToString(chars []*Char) string {
+	b := make([]rune, len(chars))
+	for i, c := range chars {
+		b[i] = c.Rune
+	}
+	return string(b)
+}
+
 type Paragraph struct {
 	ControlR *xml.UniversalElement
 	ControlT *xml.UniversalElement
@@ -302,40 +455,45 @@ type Paragraph struct {
 }
 
 func NewParagraph(p, r, t *xml.UniversalElement) *Paragraph {
-	return &Paragraph{
-		ControlR: r,
-		ControlT: t,
+	para := &Paragraph{
 		UniversalElement: xml.UniversalElement{
 			XMLName: "w:p",
 			Attrs:   p.Attrs,
-			Children: []*xml.UniversalElement{
-				p.GetElementByName("w:pPr"),
-				{
-					XMLName: "w:r",
-					Attrs:   r.Attrs,
-					Children: []*xml.UniversalElement{
-						r.GetElementByName("w:rPr"),
-					},
-				},
-			},
 		},
 	}
+	if pPr := p.GetElementByName("w:pPr"); pPr != nil {
+		para.Children = append(para.Children, pPr)
+	}
+	if t != nil {
+		para.ControlR = r
+		para.ControlT = t
+		para.Children = append(para.Children, newRun(r))
+	}
+	return para
+}
+
+// newRun creates an empty run with the attributes and properties of r
+func newRun(r *xml.UniversalElement) *xml.UniversalElement {
+	run := &xml.UniversalElement{
+		XMLName:  "w:r",
+		Attrs:    r.Attrs,
+		Children: []*xml.UniversalElement{},
+	}
+	if rPr := r.GetElementByName("w:rPr"); rPr != nil {
+		run.Children = append(run.Children, rPr)
+	}
+	return run
 }
 
 func (p *Paragraph) Insert(char *Char) {
 	if char.Rune == 0 {
+		p.ControlR = char.R
 		p.Children = append(p.Children, char.R)
 		return
 	}
 	if char.R != p.ControlR {
 		p.ControlR = char.R
-		p.Children = append(p.Children, &xml.UniversalElement{
-			XMLName: "w:r",
-			Attrs:   p.ControlR.Attrs,
-			Children: []*xml.UniversalElement{
-				p.ControlR.GetElementByName("w:rPr"),
-			},
-		})
+		p.Children = append(p.Children, newRun(p.ControlR))
 	}
 	if p.Children[p.LenChildren()-1].GetElementByName("w:t") == nil {
 		p.ControlT = char.T
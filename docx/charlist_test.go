@@ -1,6 +1,8 @@
 package docx
 
 import (
+	"bytes"
+	"errors"
 	"testing"
 
 	"github.com/saman3d/samdoc/xml"
@@ -324,3 +326,74 @@ func TestCharlist(t *testing.T) {
 	})
 
 }
+
+func TestDelims(t *testing.T) {
+	Convey("Test Delims: Non ASCII Look Ahead", t, func() {
+		pl := new(CharList)
+		pl.LoadFromParagraph(newTestParagraph("x«نام»y"))
+		lt, err := pl.LookAheadTill("»y")
+		So(err, ShouldBeNil)
+		So(lt, ShouldEqual, "«نام")
+		_, err = pl.LookAheadTill("»z")
+		So(err, ShouldNotBeNil)
+
+		pl.GoToFirst()
+		So(pl.SeekTill("نام"), ShouldBeNil)
+		So(pl.Current().Rune, ShouldEqual, '«')
+	})
+
+	Convey("Test Delims: Segments And Replace", t, func() {
+		pl := &CharList{Delims: Delims{Start: "«", End: "»"}}
+		pl.LoadFromParagraph(newTestParagraph("Dear «Na", "me», {{Age}}"))
+		segs := pl.Segments()
+		So(segs, ShouldHaveLength, 3)
+		So(segs[1].Placeholder, ShouldBeTrue)
+		So(segs[1].Text, ShouldEqual, "Name")
+		So(segs[1].Chars, ShouldHaveLength, 6)
+
+		pl = &CharList{Delims: Delims{Start: "${", End: "}"}}
+		pl.LoadFromParagraph(newTestParagraph("Dear ${Name}, ${Age}!"))
+		pl.Replace(func(p string) (string, bool) { return map[string]string{"Name": "Sara", "Age": "30"}[p], p == "Name" })
+		So(pl.String(), ShouldEqual, "Dear Sara, ${Age}!")
+	})
+
+	Convey("Test Delims: Template", t, func() {
+		file := newTestDocx(`<w:p><w:r><w:t>[[Customer]] [[range Items]][[Name]] {{Price}},[[end]]</w:t></w:r></w:p>`, nil)
+		tmp, err := NewTemplate(bytes.NewReader(file))
+		So(err, ShouldBeNil)
+		tmp.Delims("[[", "]]")
+
+		phs, err := tmp.Placeholders()
+		So(err, ShouldBeNil)
+		So(phs, ShouldHaveLength, 4)
+
+		out := new(bytes.Buffer)
+		So(tmp.ExecuteToWriter(testInvoice, out), ShouldBeNil)
+		So(documentTexts(out.Bytes()), ShouldResemble, []string{"saman pen {{Price}},book {{Price}},"})
+	})
+
+	Convey("Test Delims: Mail Merge Quotes", t, func() {
+		file := newTestDocx(`<w:p><w:r><w:t>Dear «Customer | upper», «Customer | default “-”»</w:t></w:r></w:p>`, nil)
+		tmp, err := NewTemplate(bytes.NewReader(file))
+		So(err, ShouldBeNil)
+		tmp.Delims("«", "»")
+
+		out := new(bytes.Buffer)
+		So(tmp.ExecuteToWriter(testInvoice, out), ShouldBeNil)
+		So(documentTexts(out.Bytes()), ShouldResemble, []string{"Dear SAMAN, saman"})
+	})
+
+	Convey("Test Delims: Rejected Delimiters", t, func() {
+		file := newTestDocx(`<w:p><w:r><w:t>{{Customer}}</w:t></w:r></w:p>`, nil)
+		for _, delims := range [][2]string{{"<%", "%>"}, {"&[", "]"}, {"[", ">"}} {
+			tmp, err := NewTemplate(bytes.NewReader(file))
+			So(err, ShouldBeNil)
+			tmp.Delims(delims[0], delims[1])
+
+			err = tmp.ExecuteToWriter(testInvoice, new(bytes.Buffer))
+			So(errors.Is(err, ErrInvalidDelims), ShouldBeTrue)
+			_, err = tmp.Validate(nil)
+			So(errors.Is(err, ErrInvalidDelims), ShouldBeTrue)
+		}
+	})
+}
@@ -0,0 +1,162 @@
+package docx
+
+import (
+	"html"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/saman3d/samdoc"
+	"github.com/saman3d/samdoc/xml"
+)
+
+// ContentControls makes executions turn the placeholders which couldn't be
+// resolved into plain text content controls to be filled in Word. The path of
+// the placeholder is the tag and the title of the control and the hint is
+// shown until it's filled, the path itself when the hint is empty.
+//
+//	tmp.ContentControls("Click here to enter a value")
+func (t *Template) ContentControls(hint string) *Template {
+	t.opts.controls = true
+	t.opts.hint = hint
+	return t
+}
+
+// ContentControl is a content control of a part of a document
+type ContentControl struct {
+	Tag  string
+	Part string
+	// Text is the text entered in the control, empty while it shows its hint
+	Text string
+}
+
+// ContentControls returns the content controls of the document body followed
+// by the ones of its headers and footers, such as the ones unresolved
+// placeholders are turned into, to read back the values filled in them
+func (d *Docx) ContentControls() ([]ContentControl, error) {
+	var ccs []ContentControl
+	for _, part := range d.partNames() {
+		var root xml.UniversalElement
+		err := xml.Unmarshal(d.part(part), &root)
+		if err != nil {
+			return nil, err
+		}
+		ccs = appendContentControls(ccs, part, &root)
+	}
+	return ccs, nil
+}
+
+func appendContentControls(ccs []ContentControl, part string, e *xml.UniversalElement) []ContentControl {
+	for _, c := range e.Children {
+		if c.XMLName != "w:sdt" {
+			ccs = appendContentControls(ccs, part, c)
+			continue
+		}
+		cc := ContentControl{Part: part}
+		pr := c.GetElementByName("w:sdtPr")
+		if pr != nil {
+			cc.Tag, _ = property(pr, "w:tag", "w:val")
+		}
+		content := c.GetElementByName("w:sdtContent")
+		if _, hint := property(pr, "w:showingPlcHdr", "w:val"); content != nil && !hint {
+			cc.Text = controlText(content)
+		}
+		ccs = append(ccs, cc)
+		// block level content controls can hold other ones
+		if content != nil {
+			ccs = appendContentControls(ccs, part, content)
+		}
+	}
+	return ccs
+}
+
+// controlText returns the text of the runs within the element, the ones of
+// different paragraphs on separate lines
+func controlText(e *xml.UniversalElement) string {
+	var lines []string
+	for _, para := range paragraphs(e) {
+		lines = append(lines, runsText(para))
+	}
+	if len(lines) == 0 {
+		return runsText(e)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func runsText(e *xml.UniversalElement) string {
+	if e.XMLName == "w:t" {
+		return html.UnescapeString(e.Data)
+	}
+	var s string
+	for _, c := range e.Children {
+		s += runsText(c)
+	}
+	return s
+}
+
+// property returns the attribute of the child of the element with the name
+// and whether the element has such a child, such as the w:val of the
+// properties of paragraphs, runs and content controls.
+func property(e *xml.UniversalElement, name, attr string) (string, bool) {
+	if e == nil {
+		return "", false
+	}
+	if c := e.GetElementByName(name); c != nil {
+		for _, a := range c.Attrs {
+			if a[0] == attr {
+				return html.UnescapeString(a[1]), true
+			}
+		}
+		return "", true
+	}
+	return "", false
+}
+
+// contentControl returns the content control the unresolved placeholder
+// segment is replaced with, it takes the formatting of the placeholder's
+// first char
+func (sc *scope) contentControl(seg Segment) *Char {
+	path := strings.TrimSpace(seg.Text)
+	if pl, err := samdoc.ParsePipeline(seg.Text); err == nil && len(pl.Query) != 0 {
+		path = strings.Join(pl.Query, ".")
+	}
+	hint := sc.opts.hint
+	if hint == "" {
+		hint = path
+	}
+
+	format := seg.Chars[utf8.RuneCountInString(sc.opts.delims.start())]
+	var rPr *xml.UniversalElement
+	if format.R != nil {
+		rPr = format.R.GetElementByName("w:rPr")
+	}
+	val := [][2]string{{"w:val", html.EscapeString(path)}}
+
+	pr := &xml.UniversalElement{XMLName: "w:sdtPr"}
+	run := &xml.UniversalElement{XMLName: "w:r"}
+	if rPr != nil {
+		pr.Children = append(pr.Children, rPr.Clone())
+		run.Children = append(run.Children, rPr.Clone())
+	}
+	pr.Children = append(pr.Children,
+		&xml.UniversalElement{XMLName: "w:alias", Attrs: val},
+		&xml.UniversalElement{XMLName: "w:tag", Attrs: val},
+		&xml.UniversalElement{XMLName: "w:showingPlcHdr"},
+		&xml.UniversalElement{XMLName: "w:text"},
+	)
+	run.Children = append(run.Children, &xml.UniversalElement{
+		XMLName: "w:t",
+		Attrs:   [][2]string{{"xml:space", "preserve"}},
+		Data:    html.EscapeString(hint),
+	})
+
+	return &Char{
+		R: &xml.UniversalElement{
+			XMLName: "w:sdt",
+			Children: []*xml.UniversalElement{
+				pr,
+				{XMLName: "w:sdtContent", Children: []*xml.UniversalElement{run}},
+			},
+		},
+		P: format.P,
+	}
+}
@@ -0,0 +1,68 @@
+package docx
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/saman3d/samdoc/xml"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestContentControls(t *testing.T) {
+	Convey("Test Content Controls: Unresolved Placeholders Become Controls", t, func() {
+		file := newTestDocx(`<w:p><w:r><w:rPr><w:b/></w:rPr><w:t>Dear {{Customer}}, due {{Due | date "2006"}} for {{range Items}}{{Nmae}}{{end}}</w:t></w:r></w:p>`, nil)
+		tmp, err := NewTemplate(bytes.NewReader(file))
+		So(err, ShouldBeNil)
+		tmp.ContentControls("Enter <value>")
+
+		out := new(bytes.Buffer)
+		So(tmp.ExecuteToWriter(testInvoice, out), ShouldBeNil)
+		So(documentTexts(out.Bytes()), ShouldResemble, []string{"Dear saman, due  for "})
+
+		d, err := NewDocxFromStream(bytes.NewReader(out.Bytes()), int64(out.Len()))
+		So(err, ShouldBeNil)
+		ccs, err := d.ContentControls()
+		So(err, ShouldBeNil)
+		So(ccs, ShouldResemble, []ContentControl{
+			{Tag: "Due", Part: documentPart},
+			{Tag: "Nmae", Part: documentPart},
+			{Tag: "Nmae", Part: documentPart},
+		})
+
+		var root xml.UniversalElement
+		So(xml.Unmarshal(d.content, &root), ShouldBeNil)
+		para := paragraphs(&root)[0]
+		sdt := para.Children[1]
+		So(sdt.XMLName, ShouldEqual, "w:sdt")
+		So(para.Children[2].XMLName, ShouldEqual, "w:r")
+
+		pr := sdt.GetElementByName("w:sdtPr")
+		So(pr.GetElementByName("w:alias").Attrs, ShouldResemble, [][2]string{{"w:val", "Due"}})
+		So(pr.GetElementByName("w:rPr").GetElementByName("w:b"), ShouldNotBeNil)
+		run := sdt.GetElementByName("w:sdtContent").GetElementByName("w:r")
+		So(run.GetElementByName("w:rPr").GetElementByName("w:b"), ShouldNotBeNil)
+		So(run.GetElementByName("w:t").Data, ShouldEqual, "Enter &lt;value&gt;")
+	})
+
+	Convey("Test Content Controls: Filled Controls Are Read Back", t, func() {
+		header := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:hdr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:sdt><w:sdtPr><w:tag w:val="Note"/></w:sdtPr><w:sdtContent>` +
+			`<w:p><w:r><w:t>first</w:t></w:r></w:p><w:p><w:r><w:t>second</w:t></w:r></w:p></w:sdtContent></w:sdt></w:hdr>`
+		file := newTestDocx(
+			`<w:p><w:sdt><w:sdtPr><w:alias w:val="Due"/><w:tag w:val="Due"/><w:text/></w:sdtPr><w:sdtContent>`+
+				`<w:r><w:rPr><w:b/></w:rPr><w:t>2024 &amp; </w:t></w:r><w:r><w:t>after</w:t></w:r></w:sdtContent></w:sdt></w:p>`+
+				`<w:p><w:sdt><w:sdtPr><w:tag w:val="Name"/><w:showingPlcHdr/></w:sdtPr><w:sdtContent><w:r><w:t>Name</w:t></w:r></w:sdtContent></w:sdt></w:p>`,
+			map[string]string{"word/header1.xml": header},
+		)
+		d, err := NewDocxFromStream(bytes.NewReader(file), int64(len(file)))
+		So(err, ShouldBeNil)
+
+		ccs, err := d.ContentControls()
+		So(err, ShouldBeNil)
+		So(ccs, ShouldResemble, []ContentControl{
+			{Tag: "Due", Part: documentPart, Text: "2024 & after"},
+			{Tag: "Name", Part: documentPart},
+			{Tag: "Note", Part: "word/header1.xml", Text: "first\nsecond"},
+		})
+	})
+}
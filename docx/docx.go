@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"strings"
 )
@@ -55,28 +56,55 @@ func (d *Docx) load() error {
 	return err
 }
 
+// Clone returns a copy of the document which can be executed and saved
+// independently of it
+func (d *Docx) Clone() *Docx {
+	c := &Docx{
+		zipReader:  d.zipReader,
+		proccessor: new(Processor),
+		content:    d.content,
+		headers:    make(map[string][]byte, len(d.headers)),
+		footers:    make(map[string][]byte, len(d.footers)),
+		images:     make(DocImageList, len(d.images)),
+	}
+	for name, h := range d.headers {
+		c.headers[name] = h
+	}
+	for name, f := range d.footers {
+		c.footers[name] = f
+	}
+	for image, r := range d.images {
+		c.images[image] = r
+	}
+	return c
+}
+
 // Replace replaces all occurrences of the given string with the given string
 func (d *Docx) Replace(f ReplacerFunc) error {
-	var err error
-	d.content, err = d.proccessor.LoadAndReplace(d.content, f)
+	return d.execute(&scope{opts: new(options), repf: f})
+}
+
+// Execute replaces the placeholders and expands the blocks of the document
+// using the given model
+func (d *Docx) Execute(model interface{}) error {
+	sc, err := newScope(model, new(options))
 	if err != nil {
 		return err
 	}
+	return d.execute(sc)
+}
 
-	for h := range d.headers {
-		d.headers[h], err = d.proccessor.LoadAndReplace(d.headers[h], f)
-		if err != nil {
-			return err
+func (d *Docx) execute(sc *scope) error {
+	for _, part := range d.partNames() {
+		if sc.run != nil {
+			sc.run.part = part
 		}
-	}
-
-	for foo := range d.footers {
-		d.footers[foo], err = d.proccessor.LoadAndReplace(d.footers[foo], f)
+		content, err := d.proccessor.loadAndExecute(d.part(part), sc)
 		if err != nil {
 			return err
 		}
+		d.setPart(part, content)
 	}
-
 	return nil
 }
 
@@ -95,14 +123,14 @@ func (d *Docx) Save(ioWriter io.Writer) (err error) {
 		if err != nil {
 			return err
 		}
-		if file.Name == "word/document.xml" {
+		if file.Name == documentPart {
 			writer.Write([]byte(d.content))
 		} else if strings.Contains(file.Name, "header") && len(d.headers[file.Name]) != 0 {
 			writer.Write([]byte(d.headers[file.Name]))
 		} else if strings.Contains(file.Name, "footer") && len(d.footers[file.Name]) != 0 {
 			writer.Write([]byte(d.footers[file.Name]))
 		} else if reader := getNewDocImageReader(d.images, file); reader != nil {
-			data, err := io.ReadAll(reader)
+			data, err := readImage(reader)
 			if err != nil {
 				continue
 			}
@@ -119,8 +147,8 @@ func (d *Docx) Save(ioWriter io.Writer) (err error) {
 func (d *Docx) ReplaceImageByImageName(oldImageName string, newImage io.Reader) (err error) {
 	for image := range d.images {
 		if image.Name == oldImageName {
-			d.images[image] = newImage
-			return nil
+			d.images[image], err = bufferImage(newImage)
+			return err
 		}
 	}
 	return ErrImageNotFound
@@ -130,8 +158,8 @@ func (d *Docx) ReplaceImageByImageName(oldImageName string, newImage io.Reader)
 func (d *Docx) ReplaceImageByFingerPrint(oldImageFingerprint string, newImage io.Reader) (err error) {
 	for image := range d.images {
 		if image.Fingerprint == oldImageFingerprint {
-			d.images[image] = newImage
-			return nil
+			d.images[image], err = bufferImage(newImage)
+			return err
 		}
 	}
 	return ErrImageNotFound
@@ -152,7 +180,7 @@ func (d *Docx) WriteToFile(path string) (err error) {
 // readWordDoc reads the word/document.xml file from the zip.Reader
 func (d *Docx) loadContent() error {
 	for _, f := range d.zipReader.File {
-		if f.Name == "word/document.xml" {
+		if f.Name == documentPart {
 			fo, err := f.Open()
 			if err != nil {
 				return err
@@ -236,15 +264,6 @@ func streamToByte(stream io.Reader) []byte {
 	return buf.Bytes()
 }
 
-func closeAndDelete(f *os.File) error {
-	err := f.Close()
-	if err != nil {
-		return err
-	}
-
-	return os.Remove(f.Name())
-}
-
 func zipFileToFingerprint(f *zip.File) string {
 	r, err := f.Open()
 	defer r.Close()
@@ -260,6 +279,28 @@ func zipFileToFingerprint(f *zip.File) string {
 	return ""
 }
 
+// readImage reads a replacement image from its start without moving the
+// reader, so documents sharing it can be saved and rendered at once
+func readImage(r io.Reader) ([]byte, error) {
+	if ra, ok := r.(io.ReaderAt); ok {
+		return io.ReadAll(io.NewSectionReader(ra, 0, math.MaxInt64))
+	}
+	return io.ReadAll(r)
+}
+
+// bufferImage reads a replacement image which isn't an io.ReaderAt to
+// memory so it can be read more than once
+func bufferImage(r io.Reader) (io.Reader, error) {
+	if _, ok := r.(io.ReaderAt); ok {
+		return r, nil
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(data), nil
+}
+
 func getNewDocImageReader(images DocImageList, f *zip.File) io.Reader {
 	if strings.HasPrefix(f.Name, "word/media/") {
 		if reader, ok := images[NewDocImage(f)]; ok && nil != reader {
@@ -1,10 +1,12 @@
 package docx
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"fmt"
 	"io"
 	"os"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -106,3 +108,32 @@ func filePathToReader(path string) io.Reader {
 	}
 	return nil
 }
+
+func TestReplacedImagesOfClones(t *testing.T) {
+	reader, err := ReadFile(testFile)
+	assert.Nil(t, err)
+	tmp, err := NewTemplate(reader)
+	assert.Nil(t, err)
+	image, err := os.ReadFile(newTestImage)
+	assert.Nil(t, err)
+	// a reader which can only be read once is read to memory
+	assert.Nil(t, tmp.File.ReplaceImageByImageName(testOldImage, io.MultiReader(bytes.NewReader(image))))
+
+	// clones share the replacement, each of them writes all of it
+	var wg sync.WaitGroup
+	outs := make([]*bytes.Buffer, 4)
+	for i := range outs {
+		outs[i] = new(bytes.Buffer)
+		wg.Add(1)
+		go func(out *bytes.Buffer) {
+			defer wg.Done()
+			tmp.File.Clone().Save(out)
+		}(outs[i])
+	}
+	wg.Wait()
+	for _, out := range outs {
+		d, err := NewDocxFromStream(bytes.NewReader(out.Bytes()), int64(out.Len()))
+		assert.Nil(t, err)
+		assert.True(t, d.images.Has(filePathToFingerprint(newTestImage)))
+	}
+}
@@ -0,0 +1,387 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/saman3d/samdoc/xml"
+)
+
+// HTMLOptions configure the HTML documents are rendered to
+type HTMLOptions struct {
+	// Fragment renders the content of the body alone, to be embedded in
+	// another page
+	Fragment bool
+}
+
+// WriteHTML renders the body of the document as semantic HTML without any
+// converter. Paragraphs are rendered as headings when their style is a
+// heading, or has an outline level, and as list items when they're numbered.
+// Bold, italic, underlined, struck through, superscript and subscript runs,
+// right to left paragraphs and tables, hyperlinks and images, as data URIs,
+// are rendered as well, other formatting is left out. Hyperlinks other than
+// http, https and mailto URLs and anchors are rendered as their text.
+func (d *Docx) WriteHTML(w io.Writer, opts HTMLOptions) error {
+	s, err := newStyleSheet(d)
+	if err != nil {
+		return err
+	}
+	r := &htmlRenderer{styleSheet: s, b: new(bytes.Buffer)}
+
+	var root xml.UniversalElement
+	err = xml.Unmarshal(d.content, &root)
+	if err != nil {
+		return err
+	}
+	body := root.GetElementByName("w:body")
+	if body == nil {
+		return ErrCouldntFindWordDoc
+	}
+
+	if !opts.Fragment {
+		r.b.WriteString("<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\"></head><body>")
+	}
+	r.blocks(body.Children)
+	r.closeLists(0)
+	if !opts.Fragment {
+		r.b.WriteString("</body></html>\n")
+	}
+
+	_, err = w.Write(r.b.Bytes())
+	return err
+}
+
+// ExecuteToHTML executes the template against the model and renders the
+// resulting document as HTML, see Docx.WriteHTML
+func (t *Template) ExecuteToHTML(model interface{}, w io.Writer, opts HTMLOptions, exts ...TemplateExecuteExtension) error {
+	var errs error
+	rt, err := t.rawExecute(model, exts...)
+	if rt == nil {
+		return err
+	}
+	if err != nil {
+		errs = errors.Join(errs, err)
+	}
+
+	err = rt.File.WriteHTML(w, opts)
+	if err != nil {
+		return errors.Join(ErrFatalFailure, err)
+	}
+
+	return errs
+}
+
+type htmlRenderer struct {
+	*styleSheet
+	b *bytes.Buffer
+	// lists are the lists open at every level, the item of the deepest
+	// one is open as well
+	lists []listFormat
+}
+
+// blocks renders paragraphs, tables and block level content controls
+func (r *htmlRenderer) blocks(es []*xml.UniversalElement) {
+	for _, e := range es {
+		switch e.XMLName {
+		case "w:p":
+			r.paragraph(e)
+		case "w:tbl":
+			r.closeLists(0)
+			r.table(e)
+		case "w:sdt":
+			if content := e.GetElementByName("w:sdtContent"); content != nil {
+				r.blocks(content.Children)
+			}
+		case "w:customXml":
+			r.blocks(e.Children)
+		}
+	}
+}
+
+func (r *htmlRenderer) paragraph(p *xml.UniversalElement) {
+	pPr := p.GetElementByName("w:pPr")
+	style, _ := property(pPr, "w:pStyle", "w:val")
+
+	var attrs string
+	if r.paragraphToggle(pPr, style, "w:bidi") {
+		attrs += ` dir="rtl"`
+	}
+	if jc, ok := property(pPr, "w:jc", "w:val"); ok {
+		if align := textAlign(jc); align != "" {
+			attrs += ` style="text-align:` + align + `"`
+		}
+	}
+
+	if level, format, ok := r.listLevel(pPr, style); ok {
+		r.listItem(level, format)
+		r.b.WriteString("<li" + attrs + ">")
+		r.inline(p.Children)
+		return
+	}
+
+	r.closeLists(0)
+	tag := "p"
+	if level := r.headingLevel(pPr, style); level != 0 {
+		tag = "h" + strconv.Itoa(level)
+	}
+	r.b.WriteString("<" + tag + attrs + ">")
+	r.inline(p.Children)
+	r.b.WriteString("</" + tag + ">")
+}
+
+func textAlign(jc string) string {
+	switch jc {
+	case "left", "start":
+		return "left"
+	case "center":
+		return "center"
+	case "right", "end":
+		return "right"
+	case "both", "distribute":
+		return "justify"
+	}
+	return ""
+}
+
+// listItem opens the lists up to the level and closes the previous item of
+// the level, the item itself is left to the caller
+func (r *htmlRenderer) listItem(level int, format listFormat) {
+	r.closeLists(level + 1)
+	if len(r.lists) == level+1 {
+		if r.lists[level] == format {
+			r.b.WriteString("</li>")
+		} else {
+			r.closeLists(level)
+		}
+	}
+	for len(r.lists) < level+1 {
+		if format.typ != "" {
+			r.b.WriteString("<" + format.tag + ` type="` + format.typ + `">`)
+		} else {
+			r.b.WriteString("<" + format.tag + ">")
+		}
+		r.lists = append(r.lists, format)
+	}
+}
+
+// closeLists closes the lists deeper than the level along with their items
+func (r *htmlRenderer) closeLists(level int) {
+	for len(r.lists) > level {
+		r.b.WriteString("</li></" + r.lists[len(r.lists)-1].tag + ">")
+		r.lists = r.lists[:len(r.lists)-1]
+	}
+}
+
+// inline renders the runs of a paragraph and the elements holding them
+func (r *htmlRenderer) inline(es []*xml.UniversalElement) {
+	for _, e := range es {
+		switch e.XMLName {
+		case "w:r":
+			r.run(e)
+		case "w:hyperlink":
+			href := ""
+			if id, ok := attr(e, "r:id"); ok {
+				href = r.rels[id].Target
+			} else if anchor, ok := attr(e, "w:anchor"); ok {
+				href = "#" + anchor
+			}
+			if !safeURL(href) {
+				r.inline(e.Children)
+				break
+			}
+			r.b.WriteString(`<a href="` + html.EscapeString(href) + `">`)
+			r.inline(e.Children)
+			r.b.WriteString("</a>")
+		case "w:sdt":
+			if content := e.GetElementByName("w:sdtContent"); content != nil {
+				r.inline(content.Children)
+			}
+		case "w:ins", "w:smartTag", "w:fldSimple", "w:customXml":
+			r.inline(e.Children)
+		}
+	}
+}
+
+// specialChars are the self closing elements of runs standing for text
+var specialChars = map[string]string{
+	"w:tab":            "&emsp;",
+	"w:br":             "<br>",
+	"w:cr":             "<br>",
+	"w:noBreakHyphen":  "&#8209;",
+	"w:softHyphen":     "&shy;",
+	"w:lastRenderedPB": "",
+}
+
+func (r *htmlRenderer) run(run *xml.UniversalElement) {
+	var content strings.Builder
+	for _, c := range run.Children {
+		switch c.XMLName {
+		case "w:t":
+			content.WriteString(html.EscapeString(html.UnescapeString(c.Data)))
+		case "w:drawing":
+			content.WriteString(r.image(c))
+		default:
+			content.WriteString(specialChars[c.XMLName])
+		}
+	}
+	if content.Len() == 0 {
+		return
+	}
+
+	rPr := run.GetElementByName("w:rPr")
+	style, _ := property(rPr, "w:rStyle", "w:val")
+	on := func(name string) bool {
+		return r.runToggle(rPr, style, name)
+	}
+
+	s := content.String()
+	wrap := func(tag string) {
+		s = "<" + tag + ">" + s + "</" + tag + ">"
+	}
+	switch va, _ := property(rPr, "w:vertAlign", "w:val"); va {
+	case "superscript":
+		wrap("sup")
+	case "subscript":
+		wrap("sub")
+	}
+	if on("w:strike") || on("w:dstrike") {
+		wrap("s")
+	}
+	if u, ok := property(rPr, "w:u", "w:val"); ok && u != "none" {
+		wrap("u")
+	}
+	if on("w:i") {
+		wrap("em")
+	}
+	if on("w:b") {
+		wrap("strong")
+	}
+	if on("w:rtl") {
+		s = `<span dir="rtl">` + s + "</span>"
+	}
+	r.b.WriteString(s)
+}
+
+// image renders the picture of a drawing as an img with a data URI
+func (r *htmlRenderer) image(drawing *xml.UniversalElement) string {
+	id, ok := findProperty(drawing, "a:blip", "r:embed")
+	if !ok {
+		return ""
+	}
+	rel, ok := r.rels[id]
+	if !ok || rel.TargetMode == "External" {
+		return ""
+	}
+	name := path.Join("word", rel.Target)
+	if strings.HasPrefix(rel.Target, "/") {
+		name = strings.TrimPrefix(rel.Target, "/")
+	}
+	data, ok, err := r.d.file(name)
+	if err != nil || !ok {
+		return ""
+	}
+
+	typ := mime.TypeByExtension(path.Ext(name))
+	if typ == "" {
+		typ = http.DetectContentType(data)
+	}
+	img := `<img src="data:` + typ + ";base64," + base64.StdEncoding.EncodeToString(data) + `"`
+	if alt, ok := findProperty(drawing, "wp:docPr", "descr"); ok {
+		img += ` alt="` + html.EscapeString(alt) + `"`
+	}
+	// extents are in EMUs, 9525 to a pixel
+	cx, _ := findProperty(drawing, "wp:extent", "cx")
+	cy, _ := findProperty(drawing, "wp:extent", "cy")
+	if w, err := strconv.Atoi(cx); err == nil {
+		img += fmt.Sprintf(` width="%d"`, w/9525)
+	}
+	if h, err := strconv.Atoi(cy); err == nil {
+		img += fmt.Sprintf(` height="%d"`, h/9525)
+	}
+	return img + ">"
+}
+
+// findProperty is property searching the descendants of the element as well
+func findProperty(e *xml.UniversalElement, name, attr string) (string, bool) {
+	if val, ok := property(e, name, attr); ok && val != "" {
+		return val, true
+	}
+	for _, c := range e.Children {
+		if val, ok := findProperty(c, name, attr); ok {
+			return val, true
+		}
+	}
+	return "", false
+}
+
+func (r *htmlRenderer) table(tbl *xml.UniversalElement) {
+	rows, headers := tableRows(tbl)
+
+	if toggle(tbl.GetElementByName("w:tblPr"), "w:bidiVisual") {
+		r.b.WriteString(`<table dir="rtl">`)
+	} else {
+		r.b.WriteString("<table>")
+	}
+	for i, cells := range rows {
+		r.b.WriteString("<tr>")
+		tag := "td"
+		if headers[i] {
+			tag = "th"
+		}
+		for _, c := range cells {
+			if c.merge == "continue" {
+				continue
+			}
+			r.b.WriteString("<" + tag)
+			if c.span > 1 {
+				r.b.WriteString(fmt.Sprintf(` colspan="%d"`, c.span))
+			}
+			if n := rowspan(rows, i, c); n > 1 {
+				r.b.WriteString(fmt.Sprintf(` rowspan="%d"`, n))
+			}
+			r.b.WriteString(">")
+
+			// lists don't continue across cells
+			lists := r.lists
+			r.lists = nil
+			r.blocks(c.e.Children)
+			r.closeLists(0)
+			r.lists = lists
+
+			r.b.WriteString("</" + tag + ">")
+		}
+		r.b.WriteString("</tr>")
+	}
+	r.b.WriteString("</table>")
+}
+
+// rowspan returns the number of rows the vertically merged cell spans
+func rowspan(rows [][]tableCell, i int, c tableCell) int {
+	if c.merge != "restart" {
+		return 1
+	}
+	n := 1
+	for _, cells := range rows[i+1:] {
+		merged := false
+		for _, below := range cells {
+			if below.col == c.col {
+				merged = below.merge == "continue"
+				break
+			}
+		}
+		if !merged {
+			break
+		}
+		n++
+	}
+	return n
+}
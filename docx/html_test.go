@@ -0,0 +1,128 @@
+package docx
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const testStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+	`<w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="heading 1"/></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Chapter"><w:name w:val="Chapter"/><w:basedOn w:val="Heading1"/></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Bullets"><w:name w:val="Bullets"/><w:pPr><w:numPr><w:numId w:val="1"/></w:numPr></w:pPr></w:style>` +
+	`<w:style w:type="character" w:styleId="Strong"><w:name w:val="Strong"/><w:rPr><w:b/></w:rPr></w:style>` +
+	`</w:styles>`
+
+const testNumbering = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:numbering xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+	`<w:abstractNum w:abstractNumId="0"><w:lvl w:ilvl="0"><w:numFmt w:val="bullet"/></w:lvl><w:lvl w:ilvl="1"><w:numFmt w:val="lowerRoman"/></w:lvl></w:abstractNum>` +
+	`<w:abstractNum w:abstractNumId="1"><w:lvl w:ilvl="0"><w:numFmt w:val="decimal"/></w:lvl></w:abstractNum>` +
+	`<w:num w:numId="1"><w:abstractNumId w:val="0"/></w:num>` +
+	`<w:num w:numId="2"><w:abstractNumId w:val="1"/></w:num>` +
+	`</w:numbering>`
+
+const testRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink" Target="https://example.com/?a=1&amp;b=2" TargetMode="External"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/image1.png"/>` +
+	`<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink" Target="JavaScript:alert(1)" TargetMode="External"/>` +
+	`</Relationships>`
+
+func TestHTML(t *testing.T) {
+	parts := map[string]string{
+		"word/styles.xml":              testStyles,
+		"word/numbering.xml":           testNumbering,
+		"word/_rels/document.xml.rels": testRels,
+		"word/media/image1.png":        "png",
+	}
+
+	Convey("Test HTML: Paragraphs and Runs", t, func() {
+		file := newTestDocx(`<w:p><w:pPr><w:pStyle w:val="Chapter"/></w:pPr><w:r><w:t>Terms</w:t></w:r></w:p>`+
+			`<w:p><w:pPr><w:outlineLvl w:val="2"/><w:jc w:val="center"/></w:pPr><w:r><w:t>Fees</w:t></w:r></w:p>`+
+			`<w:p><w:r><w:rPr><w:b/><w:i/></w:rPr><w:t>Bold</w:t></w:r><w:r><w:rPr><w:u w:val="single"/></w:rPr><w:t xml:space="preserve"> &amp; </w:t></w:r>`+
+			`<w:r><w:rPr><w:rStyle w:val="Strong"/><w:b w:val="0"/></w:rPr><w:t>plain</w:t></w:r><w:r><w:rPr><w:rStyle w:val="Strong"/><w:vertAlign w:val="superscript"/></w:rPr><w:t>2</w:t></w:r>`+
+			`<w:r><w:tab/><w:t>x</w:t><w:br/><w:t>y</w:t></w:r><w:del><w:r><w:t>gone</w:t></w:r></w:del></w:p>`+
+			`<w:p><w:pPr><w:bidi/></w:pPr><w:r><w:rPr><w:rtl/></w:rPr><w:t>سلام</w:t></w:r></w:p>`+
+			`<w:p><w:hyperlink r:id="rId1"><w:r><w:t>site</w:t></w:r></w:hyperlink> <w:hyperlink w:anchor="fees"><w:r><w:t>fees</w:t></w:r></w:hyperlink><w:hyperlink r:id="rId3"><w:r><w:t> run</w:t></w:r></w:hyperlink></w:p>`+
+			`<w:p><w:r><w:drawing><wp:inline><wp:extent cx="952500" cy="476250"/><wp:docPr id="1" name="Picture 1" descr="Logo"/>`+
+			`<a:graphic><a:graphicData><pic:pic><pic:blipFill><a:blip r:embed="rId2"/></pic:blipFill></pic:pic></a:graphicData></a:graphic></wp:inline></w:drawing></w:r></w:p>`, parts)
+		d, err := NewDocxFromStream(bytes.NewReader(file), int64(len(file)))
+		So(err, ShouldBeNil)
+
+		out := new(bytes.Buffer)
+		So(d.WriteHTML(out, HTMLOptions{Fragment: true}), ShouldBeNil)
+		So(out.String(), ShouldEqual, `<h1>Terms</h1>`+
+			`<h3 style="text-align:center">Fees</h3>`+
+			`<p><strong><em>Bold</em></strong><u> &amp; </u>plain<strong><sup>2</sup></strong>&emsp;x<br>y</p>`+
+			`<p dir="rtl"><span dir="rtl">سلام</span></p>`+
+			`<p><a href="https://example.com/?a=1&amp;b=2">site</a><a href="#fees">fees</a> run</p>`+
+			`<p><img src="data:image/png;base64,cG5n" alt="Logo" width="100" height="50"></p>`)
+
+		out.Reset()
+		So(d.WriteHTML(out, HTMLOptions{}), ShouldBeNil)
+		So(out.String(), ShouldStartWith, `<!DOCTYPE html>`)
+		So(out.String(), ShouldEndWith, "</body></html>\n")
+	})
+
+	Convey("Test HTML: Lists and Tables", t, func() {
+		file := newTestDocx(`<w:p><w:pPr><w:pStyle w:val="Bullets"/></w:pPr><w:r><w:t>a</w:t></w:r></w:p>`+
+			`<w:p><w:pPr><w:numPr><w:ilvl w:val="1"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>b</w:t></w:r></w:p>`+
+			`<w:p><w:pPr><w:pStyle w:val="Bullets"/></w:pPr><w:r><w:t>c</w:t></w:r></w:p>`+
+			`<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="2"/></w:numPr></w:pPr><w:r><w:t>d</w:t></w:r></w:p>`+
+			`<w:tbl><w:tblPr><w:bidiVisual/></w:tblPr>`+
+			`<w:tr><w:trPr><w:tblHeader/></w:trPr><w:tc><w:tcPr><w:gridSpan w:val="2"/></w:tcPr><w:p><w:r><w:t>Head</w:t></w:r></w:p></w:tc></w:tr>`+
+			`<w:tr><w:tc><w:tcPr><w:vMerge w:val="restart"/></w:tcPr><w:p><w:r><w:t>1</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>2</w:t></w:r></w:p></w:tc></w:tr>`+
+			`<w:tr><w:tc><w:tcPr><w:vMerge/></w:tcPr><w:p/></w:tc><w:tc><w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="2"/></w:numPr></w:pPr><w:r><w:t>3</w:t></w:r></w:p></w:tc></w:tr>`+
+			`</w:tbl>`, parts)
+		d, err := NewDocxFromStream(bytes.NewReader(file), int64(len(file)))
+		So(err, ShouldBeNil)
+
+		out := new(bytes.Buffer)
+		So(d.WriteHTML(out, HTMLOptions{Fragment: true}), ShouldBeNil)
+		So(out.String(), ShouldEqual, `<ul><li>a<ol type="i"><li>b</li></ol></li><li>c</li></ul><ol><li>d</li></ol>`+
+			`<table dir="rtl"><tr><th colspan="2"><p>Head</p></th></tr>`+
+			`<tr><td rowspan="2"><p>1</p></td><td><p>2</p></td></tr>`+
+			`<tr><td><ol><li>3</li></ol></td></tr></table>`)
+	})
+
+	Convey("Test HTML: Replaced Images", t, func() {
+		file := newTestDocx(`<w:p><w:r><w:drawing><a:blip r:embed="rId2"/></w:drawing></w:r></w:p>`, parts)
+		d, err := NewDocxFromStream(bytes.NewReader(file), int64(len(file)))
+		So(err, ShouldBeNil)
+		So(d.ReplaceImageByImageName("word/media/image1.png", strings.NewReader("gif")), ShouldBeNil)
+
+		// documents rendered at once read the replacement without moving it
+		var wg sync.WaitGroup
+		outs := make([]*bytes.Buffer, 4)
+		for i := range outs {
+			outs[i] = new(bytes.Buffer)
+			wg.Add(1)
+			go func(out *bytes.Buffer) {
+				defer wg.Done()
+				d.Clone().WriteHTML(out, HTMLOptions{Fragment: true})
+			}(outs[i])
+		}
+		wg.Wait()
+		for _, out := range outs {
+			So(out.String(), ShouldEqual, `<p><img src="data:image/png;base64,Z2lm"></p>`)
+		}
+
+		saved := new(bytes.Buffer)
+		So(d.Save(saved), ShouldBeNil)
+		So(bytes.Contains(saved.Bytes(), []byte("gif")), ShouldBeTrue)
+	})
+
+	Convey("Test HTML: Template", t, func() {
+		file := newTestDocx(`<w:p><w:r><w:t>Dear {{Name}}</w:t></w:r></w:p>`, nil)
+		tmp, err := NewTemplate(bytes.NewReader(file))
+		So(err, ShouldBeNil)
+
+		out := new(bytes.Buffer)
+		So(tmp.ExecuteToHTML(map[string]interface{}{"Name": "<Ana>"}, out, HTMLOptions{Fragment: true}), ShouldBeNil)
+		So(out.String(), ShouldEqual, `<p>Dear &lt;Ana&gt;</p>`)
+	})
+}
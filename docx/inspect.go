@@ -0,0 +1,123 @@
+package docx
+
+import (
+	"sort"
+
+	"github.com/saman3d/samdoc/xml"
+)
+
+const documentPart = "word/document.xml"
+
+// Placeholder is a placeholder found in a part of a document
+type Placeholder struct {
+	// Text is the content of the placeholder without its delimiters
+	Text string
+	// Part is the name of the part within the docx file, such as
+	// word/document.xml or word/header1.xml
+	Part string
+	// Paragraph is the index of the paragraph holding the placeholder among
+	// all the paragraphs of the part, those of tables included
+	Paragraph int
+	// Split reports whether the placeholder spans more than one run, which
+	// happens when it's partly formatted or edited
+	Split bool
+	// Block reports whether the placeholder is a block marker such as
+	// {{range Items}} or {{end}} rather than a value
+	Block bool
+}
+
+// Placeholders returns the placeholders of the document body followed by the
+// ones of its headers and footers, in order of appearance
+func (d *Docx) Placeholders() ([]Placeholder, error) {
+	return d.placeholders(Delims{})
+}
+
+func (d *Docx) placeholders(dl Delims) ([]Placeholder, error) {
+	var phs []Placeholder
+	for _, part := range d.partNames() {
+		var root xml.UniversalElement
+		err := xml.Unmarshal(d.part(part), &root)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, para := range paragraphs(&root) {
+			for _, seg := range paragraphSegments(para, dl) {
+				if !seg.Placeholder {
+					continue
+				}
+				phs = append(phs, newPlaceholder(part, i, seg))
+			}
+		}
+	}
+	return phs, nil
+}
+
+// Placeholders returns the placeholders of the template, see
+// Docx.Placeholders
+func (t *Template) Placeholders() ([]Placeholder, error) {
+	if t.opts.delimsErr != nil {
+		return nil, t.opts.delimsErr
+	}
+	return t.File.placeholders(t.opts.delims)
+}
+
+// partNames returns the name of the document part followed by the names of
+// the headers and footers in order
+func (d *Docx) partNames() []string {
+	headers := make([]string, 0, len(d.headers))
+	for h := range d.headers {
+		headers = append(headers, h)
+	}
+	sort.Strings(headers)
+
+	footers := make([]string, 0, len(d.footers))
+	for f := range d.footers {
+		footers = append(footers, f)
+	}
+	sort.Strings(footers)
+
+	return append(append([]string{documentPart}, headers...), footers...)
+}
+
+// part returns the content of the part with the name
+func (d *Docx) part(name string) []byte {
+	if name == documentPart {
+		return d.content
+	}
+	if h, ok := d.headers[name]; ok {
+		return h
+	}
+	return d.footers[name]
+}
+
+func (d *Docx) setPart(name string, content []byte) {
+	if name == documentPart {
+		d.content = content
+		return
+	}
+	if _, ok := d.headers[name]; ok {
+		d.headers[name] = content
+		return
+	}
+	d.footers[name] = content
+}
+
+func newPlaceholder(part string, paragraph int, seg Segment) Placeholder {
+	return Placeholder{
+		Text:      seg.Text,
+		Part:      part,
+		Paragraph: paragraph,
+		Split:     isSplit(seg),
+		Block:     parseBlockMarker(seg.Text).Kind != blockNone,
+	}
+}
+
+func isSplit(seg Segment) bool {
+	for _, c := range seg.Chars {
+		if c.R != seg.Chars[0].R {
+			return true
+		}
+	}
+	return false
+}
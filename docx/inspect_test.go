@@ -0,0 +1,43 @@
+package docx
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPlaceholders(t *testing.T) {
+	Convey("Test Placeholders: Body, Tables, Headers And Footers", t, func() {
+		header := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:hdr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:p><w:r><w:t>{{Company}}</w:t></w:r></w:p></w:hdr>`
+		footer := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:ftr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:p><w:r><w:t>page {{Page}}</w:t></w:r></w:p></w:ftr>`
+		file := newTestDocx(
+			`<w:p><w:r><w:t>Dear {{Cust</w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>omer.Name}},</w:t></w:r></w:p>`+
+				`<w:tbl><w:tr><w:tc><w:p><w:r><w:t>{{range Items}}</w:t></w:r></w:p></w:tc></w:tr>`+
+				`<w:tr><w:tc><w:p><w:r><w:t>{{Title | upper}}</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>{{Price}}</w:t></w:r></w:p></w:tc></w:tr>`+
+				`<w:tr><w:tc><w:p><w:r><w:t>{{end}}</w:t></w:r></w:p></w:tc></w:tr></w:tbl>`+
+				`<w:p><w:r><w:t>{{Total &amp; Tax}}</w:t></w:r></w:p>`,
+			map[string]string{
+				"word/footer1.xml": footer,
+				"word/header1.xml": header,
+			},
+		)
+
+		tmp, err := NewTemplate(bytes.NewReader(file))
+		So(err, ShouldBeNil)
+		phs, err := tmp.Placeholders()
+		So(err, ShouldBeNil)
+		So(phs, ShouldResemble, []Placeholder{
+			{Text: "Customer.Name", Part: "word/document.xml", Paragraph: 0, Split: true},
+			{Text: "range Items", Part: "word/document.xml", Paragraph: 1, Block: true},
+			{Text: "Title | upper", Part: "word/document.xml", Paragraph: 2},
+			{Text: "Price", Part: "word/document.xml", Paragraph: 3},
+			{Text: "end", Part: "word/document.xml", Paragraph: 4, Block: true},
+			{Text: "Total & Tax", Part: "word/document.xml", Paragraph: 5},
+			{Text: "Company", Part: "word/header1.xml", Paragraph: 0},
+			{Text: "Page", Part: "word/footer1.xml", Paragraph: 0},
+		})
+	})
+}
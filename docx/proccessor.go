@@ -13,17 +13,29 @@ func (p *Processor) LoadElement(doc *xml.UniversalElement) {
 }
 
 func (p *Processor) LoadAndReplace(inp []byte, f ReplacerFunc) ([]byte, error) {
+	return p.loadAndExecute(inp, &scope{opts: new(options), repf: f})
+}
+
+func (p *Processor) Replace(repfunc ReplacerFunc) ([]byte, error) {
+	return p.execute(&scope{opts: new(options), repf: repfunc})
+}
+
+func (p *Processor) WalkAndReplace(start *xml.UniversalElement, repf ReplacerFunc) error {
+	return p.walk(start, &scope{opts: new(options), repf: repf})
+}
+
+func (p *Processor) loadAndExecute(inp []byte, sc *scope) ([]byte, error) {
 	var contentRoot xml.UniversalElement
 	err := xml.Unmarshal(inp, &contentRoot)
 	if err != nil {
 		return nil, err
 	}
 	p.Document = &contentRoot
-	return p.Replace(f)
+	return p.execute(sc)
 }
 
-func (p *Processor) Replace(repfunc ReplacerFunc) ([]byte, error) {
-	err := p.WalkAndReplace(p.Document, repfunc)
+func (p *Processor) execute(sc *scope) ([]byte, error) {
+	err := p.walk(p.Document, sc)
 	if err != nil {
 		return nil, err
 	}
@@ -31,26 +43,6 @@ func (p *Processor) Replace(repfunc ReplacerFunc) ([]byte, error) {
 	return xml.Marshal(p.Document)
 }
 
-func (p *Processor) WalkAndReplace(start *xml.UniversalElement, repf ReplacerFunc) error {
-	for _, child := range start.Children {
-		if len(child.Children) == 0 {
-			continue
-		}
-		if e := child.GetElementByIndx(0); p != nil && e.XMLName == "w:p" {
-			err := p.ProccessReplace(child, repf)
-			if err != nil {
-				return err
-			}
-			continue
-		}
-		err := p.WalkAndReplace(child, repf)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
 func (p *Processor) ProccessReplace(con *xml.UniversalElement, repf ReplacerFunc) error {
 	list := new(CharList)
 	list.LoadFromElement(con)
@@ -0,0 +1,342 @@
+package docx
+
+import (
+	stdxml "encoding/xml"
+	"html"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/saman3d/samdoc/xml"
+)
+
+// file returns the content of the file of the docx archive with the name,
+// images replaced by ReplaceImageByImageName and the like included
+func (d *Docx) file(name string) ([]byte, bool, error) {
+	for _, f := range d.zipReader.File {
+		if f.Name != name {
+			continue
+		}
+		if reader := getNewDocImageReader(d.images, f); reader != nil {
+			data, err := readImage(reader)
+			return data, true, err
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, true, err
+		}
+		defer rc.Close()
+		data, err := io.ReadAll(rc)
+		return data, true, err
+	}
+	return nil, false, nil
+}
+
+// relationship is a relationship of the document part to an image, a
+// hyperlink or another part
+type relationship struct {
+	ID         string `xml:"Id,attr"`
+	Target     string `xml:"Target,attr"`
+	TargetMode string `xml:"TargetMode,attr"`
+}
+
+// listFormat is the numbering format of a level of a list
+type listFormat struct {
+	// id is the numbering of the list, lists of other numberings are
+	// other lists
+	id  string
+	tag string
+	typ string
+}
+
+// styleSheet has the styles, the numbering and the relationships of the
+// document part, which paragraphs and runs are rendered with
+type styleSheet struct {
+	d      *Docx
+	styles map[string]*xml.UniversalElement
+	// nums maps the numbering ids to their abstract numberings, which map
+	// their levels to the numbering formats
+	nums      map[string]string
+	abstracts map[string]map[string]string
+	rels      map[string]relationship
+}
+
+// newStyleSheet loads the styles, numbering and relationships of the
+// document part
+func newStyleSheet(d *Docx) (*styleSheet, error) {
+	s := &styleSheet{
+		d:         d,
+		styles:    map[string]*xml.UniversalElement{},
+		nums:      map[string]string{},
+		abstracts: map[string]map[string]string{},
+	}
+
+	styles, err := d.partElement("word/styles.xml")
+	if err != nil {
+		return nil, err
+	}
+	for _, st := range styles.Children {
+		if id, ok := attr(st, "w:styleId"); ok && st.XMLName == "w:style" {
+			s.styles[id] = st
+		}
+	}
+
+	numbering, err := d.partElement("word/numbering.xml")
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range numbering.Children {
+		switch e.XMLName {
+		case "w:abstractNum":
+			id, _ := attr(e, "w:abstractNumId")
+			lvls := map[string]string{}
+			for _, lvl := range e.Children {
+				if ilvl, ok := attr(lvl, "w:ilvl"); ok && lvl.XMLName == "w:lvl" {
+					lvls[ilvl], _ = property(lvl, "w:numFmt", "w:val")
+				}
+			}
+			s.abstracts[id] = lvls
+		case "w:num":
+			id, _ := attr(e, "w:numId")
+			s.nums[id], _ = property(e, "w:abstractNumId", "w:val")
+		}
+	}
+
+	s.rels, err = d.relationships(documentPart)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// relationships returns the relationships of the part by their ids
+func (d *Docx) relationships(part string) (map[string]relationship, error) {
+	rels := map[string]relationship{}
+	dir, name := path.Split(part)
+	data, ok, err := d.file(dir + "_rels/" + name + ".rels")
+	if err != nil || !ok {
+		return rels, err
+	}
+
+	// relationships are decoded with encoding/xml, which unescapes their
+	// targets
+	var doc struct {
+		Relationships []relationship `xml:"Relationship"`
+	}
+	err = stdxml.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, err
+	}
+	for _, rel := range doc.Relationships {
+		rels[rel.ID] = rel
+	}
+	return rels, nil
+}
+
+// partElement parses the part with the name, an empty element when the
+// document has no such part
+func (d *Docx) partElement(name string) (*xml.UniversalElement, error) {
+	root := new(xml.UniversalElement)
+	data, ok, err := d.file(name)
+	if err != nil || !ok {
+		return root, err
+	}
+	return root, xml.Unmarshal(data, root)
+}
+
+func attr(e *xml.UniversalElement, name string) (string, bool) {
+	for _, a := range e.Attrs {
+		if a[0] == name {
+			return html.UnescapeString(a[1]), true
+		}
+	}
+	return "", false
+}
+
+// safeURL reports whether the target of a hyperlink can be linked to, only
+// http, https and mailto URLs and anchors are, so a javascript: target
+// isn't run by whoever opens the rendered document
+func safeURL(u string) bool {
+	u = strings.ToLower(u)
+	for _, prefix := range []string{"http:", "https:", "mailto:", "#"} {
+		if strings.HasPrefix(u, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// toggle reports whether the toggle property, such as w:b, is on
+func toggle(pr *xml.UniversalElement, name string) bool {
+	val, ok := property(pr, name, "w:val")
+	return ok && val != "0" && val != "false" && val != "off"
+}
+
+// styleChain calls fn with the style with the id and the styles it's based
+// on, until fn returns true
+func (s *styleSheet) styleChain(id string, fn func(st *xml.UniversalElement) bool) {
+	// styles based on each other are cut short
+	for i := 0; id != "" && i < 16; i++ {
+		st, ok := s.styles[id]
+		if !ok || fn(st) {
+			return
+		}
+		id, _ = property(st, "w:basedOn", "w:val")
+	}
+}
+
+func (s *styleSheet) paragraphToggle(pPr *xml.UniversalElement, style, name string) bool {
+	if _, ok := property(pPr, name, "w:val"); ok {
+		return toggle(pPr, name)
+	}
+	on := false
+	s.styleChain(style, func(st *xml.UniversalElement) bool {
+		sPr := st.GetElementByName("w:pPr")
+		if _, ok := property(sPr, name, "w:val"); ok {
+			on = toggle(sPr, name)
+			return true
+		}
+		return false
+	})
+	return on
+}
+
+// headingLevel returns the level of the heading the paragraph is, zero when
+// it's not a heading
+func (s *styleSheet) headingLevel(pPr *xml.UniversalElement, style string) int {
+	outline := func(pr *xml.UniversalElement) int {
+		lvl, ok := property(pr, "w:outlineLvl", "w:val")
+		n, err := strconv.Atoi(lvl)
+		if !ok || err != nil || n > 5 {
+			return 0
+		}
+		return n + 1
+	}
+	if level := outline(pPr); level != 0 {
+		return level
+	}
+
+	level := 0
+	s.styleChain(style, func(st *xml.UniversalElement) bool {
+		name, _ := property(st, "w:name", "w:val")
+		name = strings.ToLower(name)
+		if name == "title" {
+			level = 1
+			return true
+		}
+		if n, err := strconv.Atoi(strings.TrimPrefix(name, "heading ")); err == nil && strings.HasPrefix(name, "heading ") {
+			if n > 6 {
+				n = 6
+			}
+			level = n
+			return true
+		}
+		level = outline(st.GetElementByName("w:pPr"))
+		return level != 0
+	})
+	return level
+}
+
+// listLevel returns the level of the list the paragraph is an item of and
+// its format, from the numbering of the paragraph or of its style
+func (s *styleSheet) listLevel(pPr *xml.UniversalElement, style string) (int, listFormat, bool) {
+	numPr := pPr
+	if numPr != nil {
+		numPr = numPr.GetElementByName("w:numPr")
+	}
+	s.styleChain(style, func(st *xml.UniversalElement) bool {
+		if numPr != nil {
+			return true
+		}
+		if sPr := st.GetElementByName("w:pPr"); sPr != nil {
+			numPr = sPr.GetElementByName("w:numPr")
+		}
+		return numPr != nil
+	})
+
+	id, _ := property(numPr, "w:numId", "w:val")
+	if id == "" || id == "0" {
+		return 0, listFormat{}, false
+	}
+	ilvl, _ := property(numPr, "w:ilvl", "w:val")
+	level, err := strconv.Atoi(ilvl)
+	if err != nil || level < 0 || level > 8 {
+		level = 0
+	}
+
+	format := listFormat{id: id, tag: "ol"}
+	switch s.abstracts[s.nums[id]][strconv.Itoa(level)] {
+	case "bullet", "none", "":
+		format.tag = "ul"
+	case "lowerLetter":
+		format.typ = "a"
+	case "upperLetter":
+		format.typ = "A"
+	case "lowerRoman":
+		format.typ = "i"
+	case "upperRoman":
+		format.typ = "I"
+	}
+	return level, format, true
+}
+
+// runToggle reports whether the toggle property of the run, such as w:b, is
+// on, from the properties of the run or its character style
+func (s *styleSheet) runToggle(rPr *xml.UniversalElement, style, name string) bool {
+	if _, ok := property(rPr, name, "w:val"); ok {
+		return toggle(rPr, name)
+	}
+	on := false
+	s.styleChain(style, func(st *xml.UniversalElement) bool {
+		sPr := st.GetElementByName("w:rPr")
+		if _, ok := property(sPr, name, "w:val"); ok {
+			on = toggle(sPr, name)
+			return true
+		}
+		return false
+	})
+	return on
+}
+
+// tableCell is a cell of a table along with the column it starts at
+type tableCell struct {
+	e     *xml.UniversalElement
+	col   int
+	span  int
+	merge string
+}
+
+// tableRows returns the cells of every row of the table and whether the
+// rows are header rows
+func tableRows(tbl *xml.UniversalElement) (rows [][]tableCell, headers []bool) {
+	for _, tr := range tbl.Children {
+		if tr.XMLName != "w:tr" {
+			continue
+		}
+		headers = append(headers, toggle(tr.GetElementByName("w:trPr"), "w:tblHeader"))
+		var cells []tableCell
+		col := 0
+		for _, tc := range tr.Children {
+			if tc.XMLName != "w:tc" {
+				continue
+			}
+			tcPr := tc.GetElementByName("w:tcPr")
+			span := 1
+			if gs, ok := property(tcPr, "w:gridSpan", "w:val"); ok {
+				if n, err := strconv.Atoi(gs); err == nil && n > 1 {
+					span = n
+				}
+			}
+			merge, ok := property(tcPr, "w:vMerge", "w:val")
+			if ok && merge == "" {
+				merge = "continue"
+			}
+			cells = append(cells, tableCell{e: tc, col: col, span: span, merge: merge})
+			col += span
+		}
+		rows = append(rows, cells)
+	}
+	return rows, headers
+}
@@ -0,0 +1,92 @@
+package docx
+
+import (
+	"os"
+	"strings"
+)
+
+// ChainReplacerFuncs creates a ReplacerFunc resolving placeholders with the
+// first of the funcs that resolves them
+//
+//	m, _ := tmp.StructReplacerFunc(model)
+//	ChainReplacerFuncs(m, PrefixReplacerFunc("company", settings), EnvReplacerFunc)
+func ChainReplacerFuncs(fs ...ReplacerFunc) ReplacerFunc {
+	return func(placeholder string) (string, bool) {
+		for _, f := range fs {
+			if val, ok := f(placeholder); ok {
+				return val, true
+			}
+		}
+		return "", false
+	}
+}
+
+// PrefixReplacerFunc creates a ReplacerFunc resolving the placeholders within
+// the namespace of the prefix with f, the prefix and its dot are trimmed
+// before f is called so {{company.Name | upper}} is resolved as
+// {{Name | upper}}
+func PrefixReplacerFunc(prefix string, f ReplacerFunc) ReplacerFunc {
+	prefix += "."
+	return func(placeholder string) (string, bool) {
+		placeholder = strings.TrimSpace(placeholder)
+		if !strings.HasPrefix(placeholder, prefix) {
+			return "", false
+		}
+		return f(strings.TrimPrefix(placeholder, prefix))
+	}
+}
+
+// DefaultReplacerFunc creates a ReplacerFunc resolving the placeholders f
+// can't resolve to the default value
+func DefaultReplacerFunc(f ReplacerFunc, def string) ReplacerFunc {
+	return func(placeholder string) (string, bool) {
+		if val, ok := f(placeholder); ok {
+			return val, true
+		}
+		return def, true
+	}
+}
+
+// EnvReplacerFunc resolves placeholders naming environment variables to their
+// values, use it with PrefixReplacerFunc to keep them in a namespace
+//
+//	PrefixReplacerFunc("env", EnvReplacerFunc) // {{env.HOSTNAME}}
+func EnvReplacerFunc(placeholder string) (string, bool) {
+	return os.LookupEnv(strings.TrimSpace(placeholder))
+}
+
+// Fallback adds funcs resolving the placeholders the model of an execution
+// can't resolve, in order, such as settings shared by every document. Blocks
+// are still executed against the model.
+//
+//	settings, _ := tmp.StructReplacerFunc(map[string]interface{}{"Name": "ACME"})
+//	tmp.Fallback(docx.PrefixReplacerFunc("company", settings))
+func (t *Template) Fallback(fs ...ReplacerFunc) *Template {
+	t.opts.fallbacks = append(t.opts.fallbacks, fs...)
+	return t
+}
+
+// StructReplacerFunc is NewStructReplacerFunc with the formatters and locale
+// the template has when a placeholder is resolved, and the field names, such
+// as JSONTags, it has when the func is created
+func (t *Template) StructReplacerFunc(model interface{}) (ReplacerFunc, error) {
+	return newStructReplacerFunc(model, &t.opts)
+}
+
+// resolver returns a ReplacerFunc resolving placeholders with f and then with
+// the fallbacks of the options
+func (o *options) resolver(f ReplacerFunc) ReplacerFunc {
+	if len(o.fallbacks) == 0 {
+		return f
+	}
+	return ChainReplacerFuncs(f, o.localize(ChainReplacerFuncs(o.fallbacks...)))
+}
+
+// localize transliterates the values f resolves placeholders to with the
+// locale of the options, if any
+func (o *options) localize(f ReplacerFunc) ReplacerFunc {
+	if o.locale == nil {
+		return f
+	}
+	return NewLocaleReplacerFunc(f, o.locale)
+}
@@ -0,0 +1,112 @@
+package docx
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/saman3d/samdoc"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestReplacerComposition(t *testing.T) {
+	settings, err := NewStructReplacerFunc(map[string]interface{}{"Name": "acme", "Phone": "021 1234"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	model, err := NewStructReplacerFunc(testInvoice)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Convey("Test Replacers: First Match Chain", t, func() {
+		f := ChainReplacerFuncs(model, settings)
+		val, ok := f("Customer")
+		So(ok, ShouldBeTrue)
+		So(val, ShouldEqual, "saman")
+		val, ok = f("Name | upper")
+		So(ok, ShouldBeTrue)
+		So(val, ShouldEqual, "ACME")
+		_, ok = f("Missing")
+		So(ok, ShouldBeFalse)
+	})
+
+	Convey("Test Replacers: Prefix Namespaces", t, func() {
+		f := PrefixReplacerFunc("company", settings)
+		val, ok := f(" company.Name | upper ")
+		So(ok, ShouldBeTrue)
+		So(val, ShouldEqual, "ACME")
+		_, ok = f("Name")
+		So(ok, ShouldBeFalse)
+		_, ok = f("companyName")
+		So(ok, ShouldBeFalse)
+	})
+
+	Convey("Test Replacers: Default Value", t, func() {
+		f := DefaultReplacerFunc(model, "-")
+		val, ok := f("Customer")
+		So(ok, ShouldBeTrue)
+		So(val, ShouldEqual, "saman")
+		val, ok = f("Missing")
+		So(ok, ShouldBeTrue)
+		So(val, ShouldEqual, "-")
+	})
+
+	Convey("Test Replacers: Environment Variables", t, func() {
+		t.Setenv("SAMDOC_TEST_HOST", "docs01")
+		f := PrefixReplacerFunc("env", EnvReplacerFunc)
+		val, ok := f("env.SAMDOC_TEST_HOST")
+		So(ok, ShouldBeTrue)
+		So(val, ShouldEqual, "docs01")
+		_, ok = f("env.SAMDOC_TEST_MISSING")
+		So(ok, ShouldBeFalse)
+	})
+
+	Convey("Test Replacers: Executing Templates Against A ReplacerFunc", t, func() {
+		file := newTestDocx(`<w:p><w:r><w:t>{{if company.Name}}{{Customer}} at {{company.Name | upper}}{{end}}</w:t></w:r></w:p>`+
+			`<w:p><w:r><w:t>{{Missing}}</w:t></w:r></w:p>`, nil)
+		tmp, err := NewTemplate(bytes.NewReader(file))
+		So(err, ShouldBeNil)
+
+		out := new(bytes.Buffer)
+		f := ChainReplacerFuncs(model, PrefixReplacerFunc("company", settings))
+		So(tmp.ExecuteToWriter(f, out), ShouldBeNil)
+		So(documentTexts(out.Bytes()), ShouldResemble, []string{"saman at ACME", "{{Missing}}"})
+
+		out.Reset()
+		So(tmp.ExecuteToWriter(func(string) (string, bool) { return "x", true }, out), ShouldBeNil)
+		So(documentTexts(out.Bytes()), ShouldResemble, []string{"x at x", "x"})
+	})
+
+	Convey("Test Replacers: Template Fallbacks", t, func() {
+		file := newTestDocx(`<w:p><w:r><w:t>{{range Items}}{{Name}} {{company.Phone}},{{end}} {{Missing}}</w:t></w:r></w:p>`, nil)
+		tmp, err := NewTemplate(bytes.NewReader(file))
+		So(err, ShouldBeNil)
+		tmp.SetLocale(samdoc.Persian).Fallback(PrefixReplacerFunc("company", settings), DefaultReplacerFunc(NilReplacerFunc, "?"))
+
+		out := new(bytes.Buffer)
+		So(tmp.ExecuteToWriter(testInvoice, out), ShouldBeNil)
+		So(documentTexts(out.Bytes()), ShouldResemble, []string{"pen ۰۲۱ ۱۲۳۴,book ۰۲۱ ۱۲۳۴, ?"})
+
+		issues, err := tmp.Validate(testInvoice)
+		So(err, ShouldBeNil)
+		So(issues, ShouldBeEmpty)
+	})
+
+	Convey("Test Replacers: Struct Replacers Of A Template", t, func() {
+		file := newTestDocx(`<w:p><w:r><w:t>{{Customer | shout}} {{company.Total | number}}</w:t></w:r></w:p>`, nil)
+		tmp, err := NewTemplate(bytes.NewReader(file))
+		So(err, ShouldBeNil)
+		company, err := tmp.StructReplacerFunc(map[string]interface{}{"Total": 1200})
+		So(err, ShouldBeNil)
+		tmp.Funcs(samdoc.FuncMap{"shout": func(v interface{}, _ ...string) (interface{}, error) {
+			return fmt.Sprint(v, "!"), nil
+		}}).SetLocale(samdoc.Persian)
+
+		f, err := tmp.StructReplacerFunc(testInvoice)
+		So(err, ShouldBeNil)
+		out := new(bytes.Buffer)
+		So(tmp.ExecuteToWriter(ChainReplacerFuncs(f, PrefixReplacerFunc("company", company)), out), ShouldBeNil)
+		So(documentTexts(out.Bytes()), ShouldResemble, []string{"saman! ۱٬۲۰۰"})
+	})
+}
@@ -0,0 +1,120 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var ErrInvalidDocumentName = errors.New("invalid document name")
+
+// Sink receives the documents of a batch, Create is called from many
+// goroutines at once
+type Sink interface {
+	// Create returns the writer the document of the model at index indx of
+	// the batch is written to, it's closed once the document is written
+	Create(indx int, model interface{}) (io.WriteCloser, error)
+}
+
+// SinkFunc is a writer factory used as a Sink
+type SinkFunc func(indx int, model interface{}) (io.WriteCloser, error)
+
+func (f SinkFunc) Create(indx int, model interface{}) (io.WriteCloser, error) {
+	return f(indx, model)
+}
+
+// NameFunc names the document of the model at index indx of a batch, a nil
+// NameFunc names them 1.docx, 2.docx and so on
+type NameFunc func(indx int, model interface{}) string
+
+func (f NameFunc) name(indx int, model interface{}) string {
+	if f == nil {
+		return fmt.Sprintf("%d.docx", indx+1)
+	}
+	return f(indx, model)
+}
+
+// DirSink writes the documents of a batch as files of the directory, names
+// may contain subdirectories which are created as needed. Names leading out
+// of the directory, such as names built from the data of models with "..",
+// fail with ErrInvalidDocumentName.
+func DirSink(dir string, name NameFunc) Sink {
+	return SinkFunc(func(indx int, model interface{}) (io.WriteCloser, error) {
+		n := name.name(indx, model)
+		path := filepath.Join(dir, n)
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("%w: %q is outside of the directory", ErrInvalidDocumentName, n)
+		}
+		err = os.MkdirAll(filepath.Dir(path), 0o755)
+		if err != nil {
+			return nil, err
+		}
+		return os.Create(path)
+	})
+}
+
+// ZipSink writes the documents of a batch as the entries of a zip archive,
+// Close has to be called once the batch is done to complete the archive.
+// Names leading out of the archive, such as absolute names or names with
+// "..", and names used twice fail with ErrInvalidDocumentName.
+type ZipSink struct {
+	mu    sync.Mutex
+	w     *zip.Writer
+	name  NameFunc
+	names map[string]bool
+}
+
+func NewZipSink(w io.Writer, name NameFunc) *ZipSink {
+	return &ZipSink{w: zip.NewWriter(w), name: name, names: map[string]bool{}}
+}
+
+func (z *ZipSink) Create(indx int, model interface{}) (io.WriteCloser, error) {
+	n := z.name.name(indx, model)
+	// archivers extracting on windows take backslashes for separators too
+	name := path.Clean(strings.ReplaceAll(n, "\\", "/"))
+	if name == "." || name == ".." || strings.HasPrefix(name, "../") || strings.HasPrefix(name, "/") {
+		return nil, fmt.Errorf("%w: %q is outside of the archive", ErrInvalidDocumentName, n)
+	}
+
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	if z.names[name] {
+		return nil, fmt.Errorf("%w: %q is used twice", ErrInvalidDocumentName, n)
+	}
+	z.names[name] = true
+	return &zipEntry{sink: z, name: name}, nil
+}
+
+// Close writes the central directory of the archive
+func (z *ZipSink) Close() error {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	return z.w.Close()
+}
+
+// zipEntry buffers a document until it's closed, the entries of a zip
+// archive have to be written one at a time
+type zipEntry struct {
+	bytes.Buffer
+	sink *ZipSink
+	name string
+}
+
+func (e *zipEntry) Close() error {
+	e.sink.mu.Lock()
+	defer e.sink.mu.Unlock()
+	w, err := e.sink.w.Create(e.name)
+	if err != nil {
+		return err
+	}
+	_, err = e.WriteTo(w)
+	return err
+}
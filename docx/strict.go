@@ -0,0 +1,41 @@
+package docx
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrUnresolvedPlaceholder = errors.New("unresolved placeholder")
+
+// UnresolvedPlaceholder is a placeholder a strict execution couldn't resolve
+type UnresolvedPlaceholder struct {
+	Part string
+	Text string
+}
+
+// UnresolvedError is returned by strict executions leaving placeholders
+// unresolved, it matches ErrUnresolvedPlaceholder with errors.Is
+type UnresolvedError struct {
+	Placeholders []UnresolvedPlaceholder
+}
+
+func (e *UnresolvedError) Error() string {
+	phs := make([]string, len(e.Placeholders))
+	for i, ph := range e.Placeholders {
+		phs[i] = fmt.Sprintf("%s: %q", ph.Part, ph.Text)
+	}
+	return fmt.Sprintf("%d unresolved placeholders: %s", len(phs), strings.Join(phs, ", "))
+}
+
+func (e *UnresolvedError) Is(target error) bool {
+	return target == ErrUnresolvedPlaceholder
+}
+
+// Strict makes executions fail with an UnresolvedError listing the
+// placeholders which couldn't be resolved, once each, joined with the other
+// errors of the execution, nothing is written then
+func (t *Template) Strict() *Template {
+	t.opts.strict = true
+	return t
+}
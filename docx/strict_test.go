@@ -0,0 +1,61 @@
+package docx
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestStrict(t *testing.T) {
+	header := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:hdr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:p><w:r><w:t>{{Compnay}}</w:t></w:r></w:p></w:hdr>`
+	file := newTestDocx(
+		`<w:p><w:r><w:t>{{Customer}} {{Nmae}}</w:t></w:r></w:p>`+
+			`<w:p><w:r><w:t>{{range Items}}{{Name}} {{Prise}},{{end}}</w:t></w:r></w:p>`,
+		map[string]string{"word/header1.xml": header},
+	)
+
+	Convey("Test Strict: Unresolved Placeholders Abort Execution", t, func() {
+		tmp, err := NewTemplate(bytes.NewReader(file))
+		So(err, ShouldBeNil)
+		tmp.Strict()
+
+		out := new(bytes.Buffer)
+		err = tmp.ExecuteToWriter(testInvoice, out)
+		So(errors.Is(err, ErrUnresolvedPlaceholder), ShouldBeTrue)
+		So(out.Len(), ShouldEqual, 0)
+
+		var uerr *UnresolvedError
+		So(errors.As(err, &uerr), ShouldBeTrue)
+		So(uerr.Placeholders, ShouldResemble, []UnresolvedPlaceholder{
+			{Part: "word/document.xml", Text: "Nmae"},
+			{Part: "word/document.xml", Text: "Prise"},
+			{Part: "word/header1.xml", Text: "Compnay"},
+		})
+		So(err.Error(), ShouldStartWith, "3 unresolved placeholders: word/document.xml: \"Nmae\", ")
+	})
+
+	Convey("Test Strict: Other Errors Are Kept", t, func() {
+		tmp, err := NewTemplate(bytes.NewReader(newTestDocx(`<w:p><w:r><w:t>{{Nmae}}</w:t></w:r></w:p><w:p><w:r><w:t>{{end}}</w:t></w:r></w:p>`, nil)))
+		So(err, ShouldBeNil)
+		tmp.Strict()
+
+		err = tmp.ExecuteToWriter(testInvoice, new(bytes.Buffer))
+		So(errors.Is(err, ErrUnresolvedPlaceholder), ShouldBeTrue)
+		So(errors.Is(err, ErrUnbalancedBlock), ShouldBeTrue)
+		var uerr *UnresolvedError
+		So(errors.As(err, &uerr), ShouldBeTrue)
+	})
+
+	Convey("Test Strict: Resolved Templates Are Written", t, func() {
+		tmp, err := NewTemplate(bytes.NewReader(newTestDocx(`<w:p><w:r><w:t>{{Customer}}</w:t></w:r></w:p>`, nil)))
+		So(err, ShouldBeNil)
+		tmp.Strict()
+
+		out := new(bytes.Buffer)
+		So(tmp.ExecuteToWriter(testInvoice, out), ShouldBeNil)
+		So(documentTexts(out.Bytes()), ShouldResemble, []string{"saman"})
+	})
+}
@@ -2,16 +2,21 @@ package docx
 
 import (
 	"bytes"
+	"context"
 	"errors"
+	"fmt"
 	"io"
-	"os"
-	"os/exec"
 
 	"github.com/saman3d/samdoc"
+	"github.com/saman3d/samdoc/convert"
 )
 
 type Template struct {
 	File *Docx
+	opts options
+	// conv and formats convert executed documents to other formats
+	conv    convert.Converter
+	formats convert.Options
 }
 
 func NewTemplate(reader io.Reader) (*Template, error) {
@@ -28,36 +33,114 @@ func NewTemplate(reader io.Reader) (*Template, error) {
 	return &Template{File: doc}, nil
 }
 
-func (t *Template) rawExecute(model interface{}, exts ...TemplateExecuteExtension) error {
+// Funcs adds the formatters to the ones placeholders of the template can use,
+// a formatter with the same name as a builtin one overrides it
+//
+//	tmp.Funcs(samdoc.FuncMap{"currency": currency})
+func (t *Template) Funcs(funcs samdoc.FuncMap) *Template {
+	if t.opts.funcs == nil {
+		t.opts.funcs = make(samdoc.FuncMap, len(funcs))
+	}
+	for name, f := range funcs {
+		t.opts.funcs[name] = f
+	}
+	return t
+}
+
+// SetLocale sets the locale numbers and digits of the document, its body,
+// headers and footers, are rendered with
+//
+//	tmp.SetLocale(samdoc.Persian)
+func (t *Template) SetLocale(l *samdoc.Locale) *Template {
+	t.opts.locale = l
+	return t
+}
+
+// Delims sets the delimiters of the placeholders of the template. Delimiters
+// with "<", ">" or "&" are rejected, the previous ones are kept and
+// executions fail with ErrInvalidDelims. Arguments of placeholders delimited
+// by quotes, such as «Total | currency "IRR"», are quoted with other quotes.
+//
+//	tmp.Delims("${", "}")
+func (t *Template) Delims(start, end string) *Template {
+	d := Delims{Start: start, End: end}
+	t.opts.delimsErr = d.validate()
+	if t.opts.delimsErr == nil {
+		t.opts.delims = d
+	}
+	return t
+}
+
+// JSONTags makes placeholders address the fields of the model by the name of
+// their json tag as well, samdoc tags take precedence
+//
+//	CustomerName string `json:"customer_name"` // {{customer_name}}
+func (t *Template) JSONTags() *Template {
+	t.opts.strct = append(t.opts.strct, samdoc.WithJSONTags())
+	return t
+}
+
+// SetNilPolicy sets how nil pointers and interfaces of the model are rendered,
+// under samdoc.NilAsError their placeholders are left as they are
+func (t *Template) SetNilPolicy(p samdoc.NilPolicy) *Template {
+	t.opts.strct = append(t.opts.strct, samdoc.WithNilPolicy(p))
+	return t
+}
+
+// rawExecute executes a copy of the document of the template and returns it
+// along with the template's options, the template itself is left untouched
+// so it can be executed again and from many goroutines at once
+func (t *Template) rawExecute(model interface{}, exts ...TemplateExecuteExtension) (*Template, error) {
+	if t.opts.delimsErr != nil {
+		return nil, t.opts.delimsErr
+	}
+
 	var errs error
-	repfunc, err := NewStructReplacerFunc(model)
+	sc, err := newScope(model, &t.opts)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	err = t.File.Replace(repfunc)
+	rt := &Template{File: t.File.Clone(), opts: t.opts, conv: t.conv, formats: t.formats}
+	err = rt.File.execute(sc)
 	if err != nil {
 		errs = errors.Join(errs, err)
 	}
+	if t.opts.strict && len(sc.run.unresolved) != 0 {
+		uerr := &UnresolvedError{Placeholders: sc.run.unresolved}
+		if errs != nil {
+			return nil, errors.Join(uerr, errs)
+		}
+		return nil, uerr
+	}
 
 	for _, ext := range exts {
-		err = ext(t)
+		err = ext(rt)
 		if err != nil {
 			errs = errors.Join(errs, err)
 		}
 	}
 
-	return errs
+	return rt, errs
 }
 
+// ExecuteToWriter executes the template against the model and writes the
+// resulting document to the writer, the model is a pointer to struct, a map
+// or a ReplacerFunc such as a chain of them:
+//
+//	m, _ := tmp.StructReplacerFunc(model)
+//	tmp.ExecuteToWriter(docx.ChainReplacerFuncs(m, docx.PrefixReplacerFunc("env", docx.EnvReplacerFunc)), w)
 func (t *Template) ExecuteToWriter(model interface{}, writer io.Writer, exts ...TemplateExecuteExtension) error {
 	var errs error
-	err := t.rawExecute(model, exts...)
+	rt, err := t.rawExecute(model, exts...)
+	if rt == nil {
+		return err
+	}
 	if err != nil {
 		errs = errors.Join(errs, err)
 	}
 
-	err = t.File.Save(writer)
+	err = rt.File.Save(writer)
 	if err != nil {
 		return errors.Join(ErrFatalFailure, err)
 	}
@@ -65,45 +148,109 @@ func (t *Template) ExecuteToWriter(model interface{}, writer io.Writer, exts ...
 	return errs
 }
 
+// ExecuteToPDF executes the template against the model and converts the
+// resulting document to PDF with the converter of the template, a local
+// LibreOffice by default
 func (t *Template) ExecuteToPDF(model interface{}, exts ...TemplateExecuteExtension) ([]byte, error) {
+	return t.ExecuteToPDFContext(context.Background(), model, exts...)
+}
+
+// ExecuteToPDFContext is ExecuteToPDF bound to the context, the conversion is
+// aborted when the context is done
+//
+//	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+//	defer cancel()
+//	pdf, err := tmp.ExecuteToPDFContext(ctx, invoice)
+func (t *Template) ExecuteToPDFContext(ctx context.Context, model interface{}, exts ...TemplateExecuteExtension) ([]byte, error) {
+	return t.ExecuteTo(ctx, convert.PDF, model, exts...)
+}
+
+// ExecuteTo executes the template against the model and converts the
+// resulting document to the format with the converter of the template and
+// the options of the format, formats other than PDF need a
+// convert.FormatConverter
+//
+//	png, err := tmp.ExecuteTo(ctx, convert.PNG, invoice)
+func (t *Template) ExecuteTo(ctx context.Context, format convert.Format, model interface{}, exts ...TemplateExecuteExtension) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	var errs error
-	err := t.rawExecute(model, exts...)
+	rt, err := t.rawExecute(model, exts...)
+	if rt == nil {
+		return nil, err
+	}
 	if err != nil {
 		errs = errors.Join(errs, err)
 	}
 
-	docx, err := samdoc.NewTempFile()
+	docx := new(bytes.Buffer)
+	err = rt.File.Save(docx)
 	if err != nil {
 		return nil, errors.Join(ErrFatalFailure, err)
 	}
-	// defer closeAndDelete(docx)
 
-	err = t.File.Save(docx)
+	out, err := t.convert(ctx, format, docx.Bytes())
 	if err != nil {
 		return nil, errors.Join(ErrFatalFailure, err)
 	}
 
-	cmd := exec.Command("lowriter", "--convert-to", "pdf", docx.Name())
-	cmd.Dir = samdoc.TEMPDIR
-	err = cmd.Run()
-	if err != nil {
-		return nil, errors.Join(ErrFatalFailure, err)
-	}
+	return out, errs
+}
 
-	pdfile, err := os.Open(docx.Name() + ".pdf")
-	if err != nil {
-		return nil, errors.Join(ErrFatalFailure, err)
-	}
-	defer closeAndDelete(pdfile)
+// SetConverter sets the converter ExecuteTo and ExecuteToPDF convert
+// documents with
+//
+//	tmp.SetConverter(convert.Gotenberg("http://gotenberg:3000"))
+func (t *Template) SetConverter(c convert.Converter) *Template {
+	t.conv = c
+	return t
+}
 
-	b, err := io.ReadAll(pdfile)
-	if err != nil {
-		return nil, errors.Join(ErrFatalFailure, err)
+// SetPDFOptions sets the options of the PDFs ExecuteToPDF exports, the
+// converter of the template has to be a convert.PDFConverter
+//
+//	tmp.SetPDFOptions(convert.PDFOptions{PDFA: convert.PDFA2b})
+func (t *Template) SetPDFOptions(opts convert.PDFOptions) *Template {
+	t.formats.PDF = opts
+	return t
+}
+
+// SetConvertOptions sets the options of every format ExecuteTo converts
+// documents to
+func (t *Template) SetConvertOptions(opts convert.Options) *Template {
+	t.formats = opts
+	return t
+}
+
+func (t *Template) converter() convert.Converter {
+	if t.conv == nil {
+		return convert.LibreOffice{}
 	}
+	return t.conv
+}
 
-	return b, nil
+func (t *Template) convert(ctx context.Context, format convert.Format, docx []byte) ([]byte, error) {
+	c := t.converter()
+	if fc, ok := c.(convert.FormatConverter); ok {
+		return fc.ConvertTo(ctx, docx, format, t.formats)
+	}
+	if format != convert.PDF {
+		return nil, fmt.Errorf("%w: %s", convert.ErrUnsupportedFormat, format)
+	}
+	if t.formats.PDF == (convert.PDFOptions{}) {
+		return c.Convert(ctx, docx)
+	}
+	pc, ok := c.(convert.PDFConverter)
+	if !ok {
+		return nil, convert.ErrUnsupportedPDFOptions
+	}
+	return pc.ConvertPDF(ctx, docx, t.formats.PDF)
 }
 
+// TemplateExecuteExtension modifies the document of a single execution of a
+// template after its placeholders are replaced
 type TemplateExecuteExtension func(*Template) error
 
 func WithImageReplaceByFingerprint(ims map[string]io.Reader) TemplateExecuteExtension {
@@ -0,0 +1,304 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/saman3d/samdoc"
+	"github.com/saman3d/samdoc/convert"
+	"github.com/saman3d/samdoc/xml"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const testDocumentHead = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>`
+const testDocumentTail = `</w:body></w:document>`
+
+// newTestDocx creates a docx file from the given body and parts
+func newTestDocx(body string, parts map[string]string) []byte {
+	buf := new(bytes.Buffer)
+	w := zip.NewWriter(buf)
+	f, _ := w.Create("word/document.xml")
+	f.Write([]byte(testDocumentHead + body + testDocumentTail))
+	for name, content := range parts {
+		f, _ = w.Create(name)
+		f.Write([]byte(content))
+	}
+	w.Close()
+	return buf.Bytes()
+}
+
+// documentTexts returns the text of every paragraph of the document of a docx file
+func documentTexts(file []byte) []string {
+	d, err := NewDocxFromStream(bytes.NewReader(file), int64(len(file)))
+	So(err, ShouldBeNil)
+	var root xml.UniversalElement
+	So(xml.Unmarshal(d.content, &root), ShouldBeNil)
+	return texts(&root)
+}
+
+func TestTemplateFuncs(t *testing.T) {
+	Convey("Test Template: Custom Formatters", t, func() {
+		file := newTestDocx(`<w:p><w:r><w:t>{{Customer | upper}} owes {{Total | currency “IRR”}}</w:t></w:r></w:p>`, nil)
+		tmp, err := NewTemplate(bytes.NewReader(file))
+		So(err, ShouldBeNil)
+
+		tmp.Funcs(samdoc.FuncMap{
+			"currency": func(v interface{}, args ...string) (interface{}, error) {
+				number, _ := samdoc.FuncMap{}.Lookup("number")
+				n, err := number(v)
+				if err != nil {
+					return nil, err
+				}
+				return fmt.Sprintf("%v %s", n, args[0]), nil
+			},
+		})
+
+		out := new(bytes.Buffer)
+		err = tmp.ExecuteToWriter(&struct {
+			Customer string
+			Total    int
+		}{"saman", 1200000}, out)
+		So(err, ShouldBeNil)
+		So(documentTexts(out.Bytes()), ShouldResemble, []string{"SAMAN owes 1,200,000 IRR"})
+	})
+}
+
+func TestTemplateLocale(t *testing.T) {
+	Convey("Test Template: Persian Locale In Body, Headers And Footers", t, func() {
+		part := func(root string, text string) string {
+			return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:` + root + ` xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:p><w:r><w:t>` + text + `</w:t></w:r></w:p></w:` + root + `>`
+		}
+		file := newTestDocx(`<w:p><w:r><w:t>{{Total | number}} - {{Rate}} - {{Phone}}</w:t></w:r></w:p>`, map[string]string{
+			"word/header1.xml": part("hdr", "page {{Page}}"),
+			"word/footer1.xml": part("ftr", "{{Total}}"),
+		})
+		tmp, err := NewTemplate(bytes.NewReader(file))
+		So(err, ShouldBeNil)
+		tmp.SetLocale(samdoc.Persian)
+
+		out := new(bytes.Buffer)
+		err = tmp.ExecuteToWriter(&struct {
+			Total int
+			Rate  float64
+			Phone string
+			Page  int
+		}{1200000, 12.5, "0912-345", 3}, out)
+		So(err, ShouldBeNil)
+		So(documentTexts(out.Bytes()), ShouldResemble, []string{"۱٬۲۰۰٬۰۰۰ - ۱۲٫۵ - ۰۹۱۲-۳۴۵"})
+
+		d, err := NewDocxFromStream(bytes.NewReader(out.Bytes()), int64(out.Len()))
+		So(err, ShouldBeNil)
+		So(string(d.headers["word/header1.xml"]), ShouldContainSubstring, "page ۳")
+		So(string(d.footers["word/footer1.xml"]), ShouldContainSubstring, "۱۲۰۰۰۰۰")
+	})
+
+	Convey("Test Template: Locale Replacer Func", t, func() {
+		f := NewLocaleReplacerFunc(func(s string) (string, bool) { return "۱۴۰۲/۰۱/۰۱", true }, samdoc.English)
+		val, ok := f("Date")
+		So(ok, ShouldBeTrue)
+		So(val, ShouldEqual, "1402/01/01")
+	})
+}
+
+func TestTemplateTags(t *testing.T) {
+	Convey("Test Template: Struct And JSON Tags", t, func() {
+		file := newTestDocx(`<w:p><w:r><w:t>{{customer_name}} {{city}} {{Nickname}}.</w:t></w:r></w:p>`, nil)
+		tmp, err := NewTemplate(bytes.NewReader(file))
+		So(err, ShouldBeNil)
+		tmp.JSONTags()
+
+		out := new(bytes.Buffer)
+		err = tmp.ExecuteToWriter(&struct {
+			CustomerName string `samdoc:"customer_name" json:"name"`
+			City         string `json:"city,omitempty"`
+			Nickname     string `samdoc:",default=-"`
+		}{"Sara", "Tabriz", ""}, out)
+		So(err, ShouldBeNil)
+		So(documentTexts(out.Bytes()), ShouldResemble, []string{"Sara Tabriz -."})
+	})
+}
+
+func TestTemplateNilPolicy(t *testing.T) {
+	type model struct {
+		Name    string
+		Manager *struct{ Name string }
+	}
+
+	Convey("Test Template: Nil Values", t, func() {
+		file := newTestDocx(`<w:p><w:r><w:t>{{Name}}[{{Manager.Name}}]</w:t></w:r></w:p>`, nil)
+
+		tmp, err := NewTemplate(bytes.NewReader(file))
+		So(err, ShouldBeNil)
+		out := new(bytes.Buffer)
+		So(tmp.ExecuteToWriter(&model{Name: "Sara"}, out), ShouldBeNil)
+		So(documentTexts(out.Bytes()), ShouldResemble, []string{"Sara[]"})
+
+		tmp, err = NewTemplate(bytes.NewReader(file))
+		So(err, ShouldBeNil)
+		tmp.SetNilPolicy(samdoc.NilAsError)
+		out.Reset()
+		So(tmp.ExecuteToWriter(&model{Name: "Sara"}, out), ShouldBeNil)
+		So(documentTexts(out.Bytes()), ShouldResemble, []string{"Sara[{{Manager.Name}}]"})
+	})
+}
+
+func TestTemplateReuse(t *testing.T) {
+	Convey("Test Template: Executing Repeatedly And Concurrently", t, func() {
+		header := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:hdr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:p><w:r><w:t>{{Customer}}</w:t></w:r></w:p></w:hdr>`
+		file := newTestDocx(`<w:p><w:r><w:t>{{Customer}}: {{range Items}}{{Name}} {{end}}</w:t></w:r></w:p>`,
+			map[string]string{"word/header1.xml": header})
+		tmp, err := NewTemplate(bytes.NewReader(file))
+		So(err, ShouldBeNil)
+
+		const n = 16
+		outs := make([]*bytes.Buffer, n)
+		errs := make([]error, n)
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				outs[i] = new(bytes.Buffer)
+				errs[i] = tmp.ExecuteToWriter(&invoice{
+					Customer: fmt.Sprintf("customer %d", i),
+					Items:    make([]invoiceItem, i%3),
+				}, outs[i])
+			}(i)
+		}
+		wg.Wait()
+
+		for i := 0; i < n; i++ {
+			So(errs[i], ShouldBeNil)
+			So(documentTexts(outs[i].Bytes()), ShouldResemble, []string{
+				fmt.Sprintf("customer %d: ", i) + strings.Repeat(" ", i%3),
+			})
+			d, err := NewDocxFromStream(bytes.NewReader(outs[i].Bytes()), int64(outs[i].Len()))
+			So(err, ShouldBeNil)
+			So(string(d.headers["word/header1.xml"]), ShouldContainSubstring, fmt.Sprintf("customer %d<", i))
+		}
+
+		phs, err := tmp.Placeholders()
+		So(err, ShouldBeNil)
+		So(phs, ShouldHaveLength, 5)
+	})
+}
+
+func TestTemplateConverter(t *testing.T) {
+	Convey("Test Template: PDF Through A Converter", t, func() {
+		tmp, err := NewTemplate(bytes.NewReader(newTestDocx(`<w:p><w:r><w:t>{{Customer}}</w:t></w:r></w:p>`, nil)))
+		So(err, ShouldBeNil)
+
+		var texts []string
+		tmp.SetConverter(convert.ConverterFunc(func(ctx context.Context, docx []byte) ([]byte, error) {
+			texts = documentTexts(docx)
+			return []byte("%PDF"), nil
+		}))
+		pdf, err := tmp.ExecuteToPDF(testInvoice)
+		So(err, ShouldBeNil)
+		So(string(pdf), ShouldEqual, "%PDF")
+		So(texts, ShouldResemble, []string{"saman"})
+
+		tmp.SetConverter(convert.ConverterFunc(func(ctx context.Context, docx []byte) ([]byte, error) {
+			return nil, convert.ErrConversionFailed
+		}))
+		_, err = tmp.ExecuteToPDF(testInvoice)
+		So(errors.Is(err, ErrFatalFailure), ShouldBeTrue)
+		So(errors.Is(err, convert.ErrConversionFailed), ShouldBeTrue)
+	})
+
+	Convey("Test Template: PDF Conversion Bound To A Context", t, func() {
+		tmp, err := NewTemplate(bytes.NewReader(newTestDocx(`<w:p><w:r><w:t>{{Customer}}</w:t></w:r></w:p>`, nil)))
+		So(err, ShouldBeNil)
+		tmp.SetConverter(convert.ConverterFunc(func(ctx context.Context, docx []byte) ([]byte, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err = tmp.ExecuteToPDFContext(ctx, testInvoice)
+		So(errors.Is(err, context.DeadlineExceeded), ShouldBeTrue)
+
+		_, err = tmp.ExecuteToPDFContext(ctx, testInvoice)
+		So(err, ShouldResemble, context.DeadlineExceeded)
+	})
+}
+
+// pdfConverter records the options it's asked to export PDFs with
+type pdfConverter struct {
+	opts convert.PDFOptions
+}
+
+func (c *pdfConverter) Convert(ctx context.Context, docx []byte) ([]byte, error) {
+	return c.ConvertPDF(ctx, docx, convert.PDFOptions{})
+}
+
+func (c *pdfConverter) ConvertPDF(ctx context.Context, docx []byte, opts convert.PDFOptions) ([]byte, error) {
+	c.opts = opts
+	return []byte("%PDF"), nil
+}
+
+func TestTemplatePDFOptions(t *testing.T) {
+	Convey("Test Template: PDF Options", t, func() {
+		tmp, err := NewTemplate(bytes.NewReader(newTestDocx(`<w:p><w:r><w:t>{{Customer}}</w:t></w:r></w:p>`, nil)))
+		So(err, ShouldBeNil)
+
+		c := new(pdfConverter)
+		opts := convert.PDFOptions{PDFA: convert.PDFA2b, PageRange: "1"}
+		tmp.SetConverter(c).SetPDFOptions(opts)
+		_, err = tmp.ExecuteToPDF(testInvoice)
+		So(err, ShouldBeNil)
+		So(c.opts, ShouldResemble, opts)
+
+		tmp.SetConverter(convert.ConverterFunc(func(ctx context.Context, docx []byte) ([]byte, error) {
+			return []byte("%PDF"), nil
+		}))
+		_, err = tmp.ExecuteToPDF(testInvoice)
+		So(errors.Is(err, convert.ErrUnsupportedPDFOptions), ShouldBeTrue)
+	})
+}
+
+// formatConverter converts documents to the name of the format
+type formatConverter struct{}
+
+func (formatConverter) Convert(ctx context.Context, docx []byte) ([]byte, error) {
+	return formatConverter{}.ConvertTo(ctx, docx, convert.PDF, convert.Options{})
+}
+
+func (formatConverter) ConvertTo(ctx context.Context, docx []byte, format convert.Format, opts convert.Options) ([]byte, error) {
+	return []byte(fmt.Sprintf("%s %d", format, opts.Image.Width)), nil
+}
+
+func TestTemplateFormats(t *testing.T) {
+	Convey("Test Template: Output Formats", t, func() {
+		tmp, err := NewTemplate(bytes.NewReader(newTestDocx(`<w:p><w:r><w:t>{{Customer}}</w:t></w:r></w:p>`, nil)))
+		So(err, ShouldBeNil)
+
+		tmp.SetConverter(formatConverter{}).SetConvertOptions(convert.Options{Image: convert.ImageOptions{Width: 320}})
+		out, err := tmp.ExecuteTo(context.Background(), convert.PNG, testInvoice)
+		So(err, ShouldBeNil)
+		So(string(out), ShouldEqual, "PNG 320")
+		out, err = tmp.ExecuteToPDF(testInvoice)
+		So(err, ShouldBeNil)
+		So(string(out), ShouldEqual, "PDF 320")
+
+		tmp.SetConverter(convert.ConverterFunc(func(ctx context.Context, docx []byte) ([]byte, error) {
+			return []byte("%PDF"), nil
+		}))
+		_, err = tmp.ExecuteTo(context.Background(), convert.ODT, testInvoice)
+		So(errors.Is(err, convert.ErrUnsupportedFormat), ShouldBeTrue)
+		out, err = tmp.ExecuteTo(context.Background(), convert.PDF, testInvoice)
+		So(err, ShouldBeNil)
+		So(string(out), ShouldEqual, "%PDF")
+	})
+}
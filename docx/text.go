@@ -0,0 +1,486 @@
+package docx
+
+import (
+	"html"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/saman3d/samdoc/xml"
+)
+
+const footnotesPart = "word/footnotes.xml"
+
+// Text returns the text of the headers, the body, the footers and the
+// footnotes of the document. Paragraphs are put on lines of their own, list
+// items are preceded by their bullets or numbers and indented by tabs, the
+// cells of table rows are separated by tabs and footnotes are referenced by
+// their numbers in brackets.
+func (d *Docx) Text() (string, error) {
+	return d.text(false)
+}
+
+// WriteMarkdown renders the headers, the body, the footers and the footnotes
+// of the document as Markdown. Headings, lists, tables, hyperlinks and bold,
+// italic and struck through runs are rendered, footnotes are rendered as
+// footnotes of GitHub Flavored Markdown. Hyperlinks are kept under the same
+// terms as by WriteHTML.
+func (d *Docx) WriteMarkdown(w io.Writer) error {
+	md, err := d.text(true)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, md+"\n")
+	return err
+}
+
+func (d *Docx) text(markdown bool) (string, error) {
+	s, err := newStyleSheet(d)
+	if err != nil {
+		return "", err
+	}
+	r := &textRenderer{styleSheet: s, markdown: markdown, counters: map[string][]int{}}
+
+	// headers come before the body in reading order
+	var headers, rest []string
+	for _, name := range d.partNames() {
+		if _, ok := d.headers[name]; ok {
+			headers = append(headers, name)
+		} else {
+			rest = append(rest, name)
+		}
+	}
+
+	var parts []string
+	for _, name := range append(headers, rest...) {
+		var root xml.UniversalElement
+		err = xml.Unmarshal(d.part(name), &root)
+		if err != nil {
+			return "", err
+		}
+		container := &root
+		if body := root.GetElementByName("w:body"); body != nil {
+			container = body
+		}
+		r.rels, err = d.relationships(name)
+		if err != nil {
+			return "", err
+		}
+		if text := r.join(r.blocks(container.Children)); text != "" {
+			parts = append(parts, text)
+		}
+	}
+
+	notes, err := r.footnotes()
+	if err != nil {
+		return "", err
+	}
+	if notes != "" {
+		parts = append(parts, notes)
+	}
+	return strings.Join(parts, "\n\n"), nil
+}
+
+type textRenderer struct {
+	*styleSheet
+	markdown bool
+	// rels are the relationships of the part being rendered
+	rels map[string]relationship
+	// counters are the numbers of the items of every level of the lists
+	// by their numbering ids
+	counters map[string][]int
+}
+
+// textBlock is a rendered paragraph or table
+type textBlock struct {
+	text string
+	// item blocks are list items, which aren't separated by blank lines in
+	// Markdown
+	item bool
+}
+
+// textSpan is text of the same formatting
+type textSpan struct {
+	text                 string
+	href                 string
+	bold, italic, strike bool
+}
+
+// footnotes renders the footnotes of the document, separators left out
+func (r *textRenderer) footnotes() (string, error) {
+	root, err := r.d.partElement(footnotesPart)
+	if err != nil {
+		return "", err
+	}
+	r.rels, err = r.d.relationships(footnotesPart)
+	if err != nil {
+		return "", err
+	}
+
+	var notes []string
+	for _, note := range root.Children {
+		id, _ := attr(note, "w:id")
+		if typ, _ := attr(note, "w:type"); note.XMLName != "w:footnote" || (typ != "" && typ != "normal") {
+			continue
+		}
+		var texts []string
+		for _, b := range r.blocks(note.Children) {
+			if b.text = strings.TrimSpace(b.text); b.text != "" {
+				texts = append(texts, b.text)
+			}
+		}
+		if r.markdown {
+			notes = append(notes, "[^"+id+"]: "+strings.Join(texts, " "))
+		} else {
+			notes = append(notes, "["+id+"] "+strings.Join(texts, " "))
+		}
+	}
+	if r.markdown {
+		return strings.Join(notes, "\n\n"), nil
+	}
+	return strings.Join(notes, "\n"), nil
+}
+
+// blocks renders paragraphs, tables and block level content controls
+func (r *textRenderer) blocks(es []*xml.UniversalElement) []textBlock {
+	var blocks []textBlock
+	for _, e := range es {
+		switch e.XMLName {
+		case "w:p":
+			blocks = append(blocks, r.paragraph(e))
+		case "w:tbl":
+			blocks = append(blocks, textBlock{text: r.table(e)})
+		case "w:sdt":
+			if content := e.GetElementByName("w:sdtContent"); content != nil {
+				blocks = append(blocks, r.blocks(content.Children)...)
+			}
+		case "w:customXml":
+			blocks = append(blocks, r.blocks(e.Children)...)
+		}
+	}
+	return blocks
+}
+
+// join puts blocks on lines of their own, separated by blank lines in
+// Markdown, where empty paragraphs are left out
+func (r *textRenderer) join(blocks []textBlock) string {
+	var b strings.Builder
+	var prev *textBlock
+	for i, block := range blocks {
+		if r.markdown && block.text == "" {
+			continue
+		}
+		if prev != nil {
+			b.WriteString("\n")
+			if r.markdown && !(block.item && prev.item) {
+				b.WriteString("\n")
+			}
+		}
+		b.WriteString(block.text)
+		prev = &blocks[i]
+	}
+	return b.String()
+}
+
+var (
+	orderedLinePattern = regexp.MustCompile(`^(\d+)([.)])`)
+	bulletLinePattern  = regexp.MustCompile(`^([-+=])`)
+)
+
+func (r *textRenderer) paragraph(p *xml.UniversalElement) textBlock {
+	pPr := p.GetElementByName("w:pPr")
+	style, _ := property(pPr, "w:pStyle", "w:val")
+	text := r.spans(r.inline(p.Children, "", nil))
+	if r.markdown {
+		// indented lines are code blocks and lines starting like list
+		// items are list items in Markdown
+		text = strings.TrimLeft(text, " \t")
+		text = orderedLinePattern.ReplaceAllString(text, `$1\$2`)
+		text = bulletLinePattern.ReplaceAllString(text, `\$1`)
+	}
+
+	if level, format, ok := r.listLevel(pPr, style); ok {
+		return textBlock{text: r.marker(level, format) + text, item: true}
+	}
+	if r.markdown && text != "" {
+		if level := r.headingLevel(pPr, style); level != 0 {
+			text = strings.Repeat("#", level) + " " + text
+		}
+	}
+	return textBlock{text: text}
+}
+
+// marker returns the indentation and the bullet or number of the next item
+// of the list
+func (r *textRenderer) marker(level int, format listFormat) string {
+	counts := r.counters[format.id]
+	for len(counts) <= level {
+		counts = append(counts, 0)
+	}
+	counts[level]++
+	for i := level + 1; i < len(counts); i++ {
+		counts[i] = 0
+	}
+	r.counters[format.id] = counts
+
+	if r.markdown {
+		indent := strings.Repeat("    ", level)
+		if format.tag == "ul" {
+			return indent + "- "
+		}
+		return indent + strconv.Itoa(counts[level]) + ". "
+	}
+	indent := strings.Repeat("\t", level)
+	if format.tag == "ul" {
+		return indent + "• "
+	}
+	return indent + listNumber(counts[level], format.typ) + ". "
+}
+
+// listNumber formats the number of a list item as a letter or a roman
+// numeral for the types of ol elements
+func listNumber(n int, typ string) string {
+	switch typ {
+	case "a", "A":
+		var s []byte
+		for ; n > 0; n = (n - 1) / 26 {
+			s = append([]byte{byte('a' + (n-1)%26)}, s...)
+		}
+		if typ == "A" {
+			return strings.ToUpper(string(s))
+		}
+		return string(s)
+	case "i", "I":
+		var s strings.Builder
+		for _, numeral := range []struct {
+			value int
+			roman string
+		}{{1000, "m"}, {900, "cm"}, {500, "d"}, {400, "cd"}, {100, "c"}, {90, "xc"},
+			{50, "l"}, {40, "xl"}, {10, "x"}, {9, "ix"}, {5, "v"}, {4, "iv"}, {1, "i"}} {
+			for ; n >= numeral.value; n -= numeral.value {
+				s.WriteString(numeral.roman)
+			}
+		}
+		if typ == "I" {
+			return strings.ToUpper(s.String())
+		}
+		return s.String()
+	}
+	return strconv.Itoa(n)
+}
+
+// inline collects the spans of the runs of a paragraph and the elements
+// holding them
+func (r *textRenderer) inline(es []*xml.UniversalElement, href string, spans []textSpan) []textSpan {
+	for _, e := range es {
+		switch e.XMLName {
+		case "w:r":
+			spans = r.run(e, href, spans)
+		case "w:hyperlink":
+			link := ""
+			if id, ok := attr(e, "r:id"); ok {
+				link = r.rels[id].Target
+			} else if anchor, ok := attr(e, "w:anchor"); ok {
+				link = "#" + anchor
+			}
+			if !safeURL(link) {
+				link = ""
+			}
+			spans = r.inline(e.Children, link, spans)
+		case "w:sdt":
+			if content := e.GetElementByName("w:sdtContent"); content != nil {
+				spans = r.inline(content.Children, href, spans)
+			}
+		case "w:ins", "w:smartTag", "w:fldSimple", "w:customXml":
+			spans = r.inline(e.Children, href, spans)
+		}
+	}
+	return spans
+}
+
+func (r *textRenderer) run(run *xml.UniversalElement, href string, spans []textSpan) []textSpan {
+	var text strings.Builder
+	for _, c := range run.Children {
+		if c.XMLName == "w:t" {
+			t := html.UnescapeString(c.Data)
+			if r.markdown {
+				t = escapeMarkdown(t)
+			}
+			text.WriteString(t)
+		} else {
+			text.WriteString(r.char(c.XMLName, c))
+		}
+	}
+	if text.Len() == 0 {
+		return spans
+	}
+
+	rPr := run.GetElementByName("w:rPr")
+	style, _ := property(rPr, "w:rStyle", "w:val")
+	return append(spans, textSpan{
+		text:   text.String(),
+		href:   href,
+		bold:   r.runToggle(rPr, style, "w:b"),
+		italic: r.runToggle(rPr, style, "w:i"),
+		strike: r.runToggle(rPr, style, "w:strike") || r.runToggle(rPr, style, "w:dstrike"),
+	})
+}
+
+// char returns the text the element of a run stands for
+func (r *textRenderer) char(name string, e *xml.UniversalElement) string {
+	switch name {
+	case "w:tab":
+		return "\t"
+	case "w:br", "w:cr":
+		// page and column breaks aren't line breaks
+		if typ, _ := attr(e, "w:type"); typ == "page" || typ == "column" {
+			return ""
+		}
+		if r.markdown {
+			return "<br>"
+		}
+		return "\n"
+	case "w:noBreakHyphen":
+		return "-"
+	case "w:footnoteReference":
+		id, _ := attr(e, "w:id")
+		if r.markdown {
+			return "[^" + id + "]"
+		}
+		return "[" + id + "]"
+	}
+	return ""
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`,
+	`<`, `\<`, `>`, `\>`, `|`, `\|`, `~`, `\~`, `#`, `\#`,
+)
+
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// spans renders the spans of a paragraph, merging the ones of the same
+// formatting and link in Markdown
+func (r *textRenderer) spans(spans []textSpan) string {
+	var b strings.Builder
+	if !r.markdown {
+		for _, s := range spans {
+			b.WriteString(s.text)
+		}
+		return b.String()
+	}
+
+	var merged []textSpan
+	for _, s := range spans {
+		if n := len(merged); n != 0 {
+			last := &merged[n-1]
+			if last.href == s.href && last.bold == s.bold && last.italic == s.italic && last.strike == s.strike {
+				last.text += s.text
+				continue
+			}
+		}
+		merged = append(merged, s)
+	}
+
+	for i := 0; i < len(merged); {
+		href := merged[i].href
+		var link strings.Builder
+		for ; i < len(merged) && merged[i].href == href; i++ {
+			s := merged[i]
+			text := s.text
+			if s.strike {
+				text = emphasize(text, "~~")
+			}
+			if s.italic {
+				text = emphasize(text, "*")
+			}
+			if s.bold {
+				text = emphasize(text, "**")
+			}
+			link.WriteString(text)
+		}
+		if href == "" {
+			b.WriteString(link.String())
+		} else {
+			b.WriteString("[" + link.String() + "](" + markdownURL(href) + ")")
+		}
+	}
+	return b.String()
+}
+
+// emphasize wraps the text in the marker, leaving the surrounding white
+// space out as emphasis can't start or end with it
+func emphasize(text, marker string) string {
+	core := strings.TrimLeft(text, " \t")
+	lead := text[:len(text)-len(core)]
+	trimmed := strings.TrimRight(core, " \t")
+	if trimmed == "" {
+		return text
+	}
+	return lead + marker + trimmed + marker + core[len(trimmed):]
+}
+
+var markdownURLEscaper = strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29")
+
+func markdownURL(href string) string {
+	return markdownURLEscaper.Replace(href)
+}
+
+// table renders the rows of the table on lines of their own, cells
+// separated by tabs or, in Markdown, as a table with its first row as the
+// header row
+func (r *textRenderer) table(tbl *xml.UniversalElement) string {
+	rows, _ := tableRows(tbl)
+	cols := 0
+	texts := make([][]string, len(rows))
+	for i, cells := range rows {
+		for _, c := range cells {
+			text := ""
+			if c.merge != "continue" {
+				text = r.cell(c.e)
+			}
+			texts[i] = append(texts[i], text)
+			// spanned columns are kept as empty cells for the columns to
+			// line up
+			for j := 1; j < c.span; j++ {
+				texts[i] = append(texts[i], "")
+			}
+		}
+		if len(texts[i]) > cols {
+			cols = len(texts[i])
+		}
+	}
+
+	lines := make([]string, 0, len(texts)+1)
+	for i, cells := range texts {
+		if !r.markdown {
+			lines = append(lines, strings.Join(cells, "\t"))
+			continue
+		}
+		for len(cells) < cols {
+			cells = append(cells, "")
+		}
+		lines = append(lines, "| "+strings.Join(cells, " | ")+" |")
+		if i == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", cols))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// cell renders the paragraphs of a table cell on a single line
+func (r *textRenderer) cell(tc *xml.UniversalElement) string {
+	var texts []string
+	for _, b := range r.blocks(tc.Children) {
+		if b.text != "" {
+			texts = append(texts, strings.ReplaceAll(b.text, "\n", " "))
+		}
+	}
+	if r.markdown {
+		return strings.Join(texts, "<br>")
+	}
+	return strings.Join(texts, " ")
+}
@@ -0,0 +1,85 @@
+package docx
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const testFootnotes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:footnotes xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+	`<w:footnote w:type="separator" w:id="-1"><w:p><w:r><w:separator/></w:r></w:p></w:footnote>` +
+	`<w:footnote w:id="1"><w:p><w:r><w:footnoteRef/></w:r><w:r><w:t xml:space="preserve"> Paid in IRR.</w:t></w:r></w:p></w:footnote>` +
+	`</w:footnotes>`
+
+func TestText(t *testing.T) {
+	parts := map[string]string{
+		"word/styles.xml":              testStyles,
+		"word/numbering.xml":           testNumbering,
+		"word/_rels/document.xml.rels": testRels,
+		"word/footnotes.xml":           testFootnotes,
+		"word/header1.xml":             `<w:hdr><w:p><w:r><w:t>ACME</w:t></w:r></w:p></w:hdr>`,
+		"word/footer1.xml":             `<w:ftr><w:p><w:r><w:t>Page</w:t></w:r></w:p></w:ftr>`,
+	}
+	file := newTestDocx(`<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Invoice #7</w:t></w:r></w:p>`+
+		`<w:p><w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">Total </w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>due</w:t></w:r>`+
+		`<w:r><w:t xml:space="preserve">: 5*2</w:t></w:r><w:r><w:footnoteReference w:id="1"/></w:r></w:p>`+
+		`<w:p></w:p>`+
+		`<w:p><w:r><w:t>Terms:</w:t><w:tab/><w:t>Net</w:t></w:r><w:r><w:rPr><w:i/></w:rPr><w:t xml:space="preserve"> 30 </w:t></w:r><w:hyperlink r:id="rId1"><w:r><w:t>terms</w:t></w:r></w:hyperlink><w:hyperlink r:id="rId3"><w:r><w:t xml:space="preserve"> apply</w:t></w:r></w:hyperlink></w:p>`+
+		`<w:p><w:pPr><w:pStyle w:val="Bullets"/></w:pPr><w:r><w:t>Rent</w:t></w:r></w:p>`+
+		`<w:p><w:pPr><w:numPr><w:ilvl w:val="1"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>Deposit</w:t></w:r></w:p>`+
+		`<w:p><w:pPr><w:numPr><w:ilvl w:val="1"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>Fees</w:t></w:r></w:p>`+
+		`<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="2"/></w:numPr></w:pPr><w:r><w:t>Sign</w:t></w:r></w:p>`+
+		`<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="2"/></w:numPr></w:pPr><w:r><w:t>Return</w:t></w:r></w:p>`+
+		`<w:tbl><w:tr><w:tc><w:tcPr><w:gridSpan w:val="2"/></w:tcPr><w:p><w:r><w:t>Item|Cost</w:t></w:r></w:p></w:tc></w:tr>`+
+		`<w:tr><w:tc><w:p><w:r><w:t>Rent</w:t></w:r></w:p><w:p><w:r><w:t>May</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>100</w:t></w:r></w:p></w:tc></w:tr></w:tbl>`, parts)
+	d, err := NewDocxFromStream(bytes.NewReader(file), int64(len(file)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Convey("Test Text: Plain Text", t, func() {
+		text, err := d.Text()
+		So(err, ShouldBeNil)
+		So(text, ShouldEqual, "ACME\n\n"+
+			"Invoice #7\n"+
+			"Total due: 5*2[1]\n"+
+			"\n"+
+			"Terms:\tNet 30 terms apply\n"+
+			"• Rent\n"+
+			"\ti. Deposit\n"+
+			"\tii. Fees\n"+
+			"1. Sign\n"+
+			"2. Return\n"+
+			"Item|Cost\t\n"+
+			"Rent May\t100\n\n"+
+			"Page\n\n"+
+			"[1] Paid in IRR.")
+	})
+
+	Convey("Test Text: Markdown", t, func() {
+		out := new(bytes.Buffer)
+		So(d.WriteMarkdown(out), ShouldBeNil)
+		So(out.String(), ShouldEqual, "ACME\n\n"+
+			"# Invoice \\#7\n\n"+
+			"**Total due**: 5\\*2[^1]\n\n"+
+			"Terms:\tNet *30* [terms](https://example.com/?a=1&b=2) apply\n\n"+
+			"- Rent\n"+
+			"    1. Deposit\n"+
+			"    2. Fees\n"+
+			"1. Sign\n"+
+			"2. Return\n\n"+
+			"| Item\\|Cost |  |\n"+
+			"| --- | --- |\n"+
+			"| Rent<br>May | 100 |\n\n"+
+			"Page\n\n"+
+			"[^1]: Paid in IRR.\n")
+	})
+
+	Convey("Test Text: Numbers", t, func() {
+		So(listNumber(28, "a"), ShouldEqual, "ab")
+		So(listNumber(1994, "I"), ShouldEqual, "MCMXCIV")
+		So(listNumber(3, ""), ShouldEqual, "3")
+	})
+}
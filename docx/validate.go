@@ -0,0 +1,200 @@
+package docx
+
+import (
+	"fmt"
+
+	"github.com/saman3d/samdoc"
+	"github.com/saman3d/samdoc/xml"
+)
+
+// Issue is a problem with a placeholder of a template found by Validate
+type Issue struct {
+	Placeholder
+	Err error
+}
+
+func (i Issue) Error() string {
+	return fmt.Sprintf("%s, paragraph %d: %q: %v", i.Part, i.Paragraph, i.Text, i.Err)
+}
+
+func (i Issue) Unwrap() error {
+	return i.Err
+}
+
+// Validate checks the placeholders of the template against the type of a
+// model, either a reflect.Type or a sample model, and returns the ones which
+// can't be resolved, use unknown formatters or are unbalanced block markers.
+// A nil model only checks the formatters and the blocks, placeholders the
+// fallbacks of the template resolve are never reported.
+func (t *Template) Validate(model interface{}) ([]Issue, error) {
+	if t.opts.delimsErr != nil {
+		return nil, t.opts.delimsErr
+	}
+	schema, err := samdoc.NewSchema(model, t.opts.strct...)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []Issue
+	for _, part := range t.File.partNames() {
+		var root xml.UniversalElement
+		err := xml.Unmarshal(t.File.part(part), &root)
+		if err != nil {
+			return nil, err
+		}
+
+		v := &validator{
+			part:       part,
+			funcs:      t.opts.funcs,
+			delims:     t.opts.delims,
+			fallback:   ChainReplacerFuncs(t.opts.fallbacks...),
+			paragraphs: map[*xml.UniversalElement]int{},
+		}
+		for i, para := range paragraphs(&root) {
+			v.paragraphs[para] = i
+		}
+		v.expand(root.Children, schema)
+		issues = append(issues, v.issues...)
+	}
+	return issues, nil
+}
+
+// validator walks a part of a document the way Processor executes it,
+// collecting the issues of its placeholders
+type validator struct {
+	part       string
+	funcs      samdoc.FuncMap
+	delims     Delims
+	fallback   ReplacerFunc
+	paragraphs map[*xml.UniversalElement]int
+	issues     []Issue
+}
+
+func (v *validator) report(para *xml.UniversalElement, seg Segment, err error) {
+	v.issues = append(v.issues, Issue{
+		Placeholder: newPlaceholder(v.part, v.paragraphs[para], seg),
+		Err:         err,
+	})
+}
+
+// block returns the schema the body of the block opened by the marker is
+// resolved against
+func (v *validator) block(m blockMarker, sc *samdoc.Schema) (*samdoc.Schema, error) {
+	qry := samdoc.NewFieldQuery(m.Expr)
+	if m.Kind == blockRange {
+		isc, err := sc.Items(qry)
+		if err != nil {
+			return sc, fmt.Errorf("range %s: %w", m.Expr, err)
+		}
+		return isc, nil
+	}
+	if _, err := sc.Resolve(qry); err != nil {
+		return sc, fmt.Errorf("if %s: %w", m.Expr, err)
+	}
+	return sc, nil
+}
+
+func (v *validator) expand(elements []*xml.UniversalElement, sc *samdoc.Schema) {
+	markers := make([]blockMarker, len(elements))
+	for i, e := range elements {
+		markers[i] = elementMarker(e, v.delims)
+	}
+
+	for i := 0; i < len(elements); i++ {
+		m := markers[i]
+		if m.Kind == blockNone {
+			v.element(elements[i], sc)
+			continue
+		}
+		para, seg := markerSegment(elements[i], v.delims)
+		if !m.opens() {
+			v.report(para, seg, ErrUnbalancedBlock)
+			continue
+		}
+		end, els, err := closingMarker(markers, i)
+		if err != nil {
+			v.report(para, seg, err)
+			continue
+		}
+
+		isc, err := v.block(m, sc)
+		if err != nil {
+			v.report(para, seg, err)
+		}
+		body, elseBody := branches(elements, i, end, els)
+		v.expand(body, isc)
+		v.expand(elseBody, sc)
+		i = end
+	}
+}
+
+func (v *validator) element(e *xml.UniversalElement, sc *samdoc.Schema) {
+	switch e.XMLName {
+	case "w:p":
+		v.paragraph(e, paragraphSegments(e, v.delims), sc)
+		return
+	case "w:tr":
+		para, seg := markerSegment(e, v.delims)
+		if m, ok := rowBlock(e, v.delims); ok {
+			isc, err := v.block(m, sc)
+			if err != nil {
+				v.report(para, seg, err)
+			}
+			sc = isc
+		}
+	}
+	v.expand(e.Children, sc)
+}
+
+func (v *validator) paragraph(para *xml.UniversalElement, segs []Segment, sc *samdoc.Schema) {
+	markers := make([]blockMarker, len(segs))
+	for i, seg := range segs {
+		if seg.Placeholder {
+			markers[i] = parseBlockMarker(seg.Text)
+		}
+	}
+
+	for i := 0; i < len(segs); i++ {
+		m := markers[i]
+		switch {
+		case !segs[i].Placeholder:
+		case m.Kind == blockNone:
+			pl, err := samdoc.ParsePipeline(segs[i].Text)
+			if err == nil {
+				err = sc.Check(pl, v.funcs)
+			}
+			if _, ok := v.fallback(segs[i].Text); err != nil && !ok {
+				v.report(para, segs[i], err)
+			}
+		case !m.opens():
+			v.report(para, segs[i], ErrUnbalancedBlock)
+		default:
+			end, els, err := closingMarker(markers, i)
+			if err != nil {
+				v.report(para, segs[i], err)
+				continue
+			}
+			isc, err := v.block(m, sc)
+			if err != nil {
+				v.report(para, segs[i], err)
+			}
+			body, elseBody := branches(segs, i, end, els)
+			v.paragraph(para, body, isc)
+			v.paragraph(para, elseBody, sc)
+			i = end
+		}
+	}
+}
+
+// markerSegment returns the first placeholder of the element along with its
+// paragraph
+func markerSegment(e *xml.UniversalElement, d Delims) (*xml.UniversalElement, Segment) {
+	for _, para := range paragraphs(e) {
+		for _, seg := range paragraphSegments(para, d) {
+			if seg.Placeholder {
+				return para, seg
+			}
+		}
+	}
+	return e, Segment{}
+}
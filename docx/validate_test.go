@@ -0,0 +1,77 @@
+package docx
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/saman3d/samdoc"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestValidate(t *testing.T) {
+	footer := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:ftr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:p><w:r><w:t>{{Custmer}}</w:t></w:r></w:p></w:ftr>`
+	file := newTestDocx(
+		`<w:p><w:r><w:t>{{Customer | upper}} {{Customer | shout}}</w:t></w:r></w:p>`+
+			`<w:tbl><w:tr><w:tc><w:p><w:r><w:t>{{range Items}}</w:t></w:r></w:p></w:tc></w:tr>`+
+			`<w:tr><w:tc><w:p><w:r><w:t>{{Name}} {{Price | number}} {{Customer}} {{Prise}}</w:t></w:r></w:p></w:tc></w:tr>`+
+			`<w:tr><w:tc><w:p><w:r><w:t>{{end}}</w:t></w:r></w:p></w:tc></w:tr></w:tbl>`+
+			`<w:p><w:r><w:t>{{range Tags}}{{.}}{{end}} {{range Customer}}x{{end}} {{if Paid}}{{else}}due{{end}}</w:t></w:r></w:p>`+
+			`<w:p><w:r><w:t>{{if Notes}}</w:t></w:r></w:p>`+
+			`<w:p><w:r><w:t>{{end}}</w:t></w:r></w:p>`+
+			`<w:p><w:r><w:t>{{end}}</w:t></w:r></w:p>`+
+			`<w:p><w:r><w:t>{{if Paid}}open</w:t></w:r></w:p>`,
+		map[string]string{"word/footer1.xml": footer},
+	)
+
+	Convey("Test Validate: Against A Type", t, func() {
+		tmp, err := NewTemplate(bytes.NewReader(file))
+		So(err, ShouldBeNil)
+
+		for _, model := range []interface{}{reflect.TypeOf(invoice{}), testInvoice} {
+			issues, err := tmp.Validate(model)
+			So(err, ShouldBeNil)
+
+			type found struct {
+				Text      string
+				Part      string
+				Paragraph int
+			}
+			var got []found
+			for _, issue := range issues {
+				got = append(got, found{issue.Text, issue.Part, issue.Paragraph})
+			}
+			So(got, ShouldResemble, []found{
+				{"Customer | shout", "word/document.xml", 0},
+				{"Prise", "word/document.xml", 2},
+				{"range Customer", "word/document.xml", 4},
+				{"end", "word/document.xml", 7},
+				{"if Paid", "word/document.xml", 8},
+				{"Custmer", "word/footer1.xml", 0},
+			})
+			So(errors.Is(issues[0], samdoc.ErrUnknownFormatter), ShouldBeTrue)
+			So(errors.Is(issues[1], samdoc.ErrInvalidField), ShouldBeTrue)
+			So(errors.Is(issues[2], samdoc.ErrQueryFieldMustBeSlice), ShouldBeTrue)
+			So(errors.Is(issues[3], ErrUnbalancedBlock), ShouldBeTrue)
+			So(errors.Is(issues[4], ErrUnbalancedBlock), ShouldBeTrue)
+			So(issues[5].Error(), ShouldEqual, "word/footer1.xml, paragraph 0: \"Custmer\": invalid field")
+		}
+	})
+
+	Convey("Test Validate: Custom Formatters And Maps", t, func() {
+		tmp, err := NewTemplate(bytes.NewReader(file))
+		So(err, ShouldBeNil)
+		tmp.Funcs(samdoc.FuncMap{"shout": func(v interface{}, _ ...string) (interface{}, error) { return v, nil }})
+
+		issues, err := tmp.Validate(map[string]interface{}{})
+		So(err, ShouldBeNil)
+		So(issues, ShouldHaveLength, 2)
+		So(issues[0].Text, ShouldEqual, "end")
+		So(issues[1].Text, ShouldEqual, "if Paid")
+
+		_, err = tmp.Validate(42)
+		So(err, ShouldEqual, samdoc.ErrModelMustBePointerToStruct)
+	})
+}
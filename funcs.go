@@ -0,0 +1,294 @@
+package samdoc
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saman3d/samdoc/jalali"
+)
+
+var (
+	ErrFormatterArgs  = errors.New("invalid formatter arguments")
+	ErrFormatterValue = errors.New("value not supported by formatter")
+)
+
+// FormatterFunc formats the value of a placeholder, v is either the resolved
+// field or the result of the previous formatter of the pipeline
+type FormatterFunc func(v interface{}, args ...string) (interface{}, error)
+
+// FuncMap maps the names used in placeholders to formatters
+type FuncMap map[string]FormatterFunc
+
+var builtinFuncs = FuncMap{
+	"upper":    upper,
+	"lower":    lower,
+	"trim":     trim,
+	"default":  defaultValue,
+	"date":     date,
+	"jalali":   jalaliDate,
+	"number":   number,
+	"truncate": truncate,
+	"words":    words,
+}
+
+// Lookup returns the formatter registered with the name, falling back to the
+// builtin formatters
+func (fm FuncMap) Lookup(name string) (FormatterFunc, bool) {
+	if f, ok := fm[name]; ok {
+		return f, true
+	}
+	f, ok := builtinFuncs[name]
+	return f, ok
+}
+
+// toString converts a value to string, nil pointers and interfaces are empty
+// and fmt.Stringer takes precedence over encoding.TextMarshaler
+func toString(v interface{}) string {
+	if isNil(reflect.ValueOf(v)) {
+		return ""
+	}
+	switch s := v.(type) {
+	case string:
+		return s
+	case fmt.Stringer:
+		return s.String()
+	case encoding.TextMarshaler:
+		if b, err := s.MarshalText(); err == nil {
+			return string(b)
+		}
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// upper converts the value to upper case
+func upper(v interface{}, _ ...string) (interface{}, error) {
+	return strings.ToUpper(toString(v)), nil
+}
+
+// lower converts the value to lower case
+func lower(v interface{}, _ ...string) (interface{}, error) {
+	return strings.ToLower(toString(v)), nil
+}
+
+// trim removes the leading and trailing spaces, or the given characters
+//
+//	{{Name | trim}} {{Code | trim "0"}}
+func trim(v interface{}, args ...string) (interface{}, error) {
+	switch len(args) {
+	case 0:
+		return strings.TrimSpace(toString(v)), nil
+	case 1:
+		return strings.Trim(toString(v), args[0]), nil
+	}
+	return nil, ErrFormatterArgs
+}
+
+// defaultValue replaces zero values, nil pointers and empty strings, slices
+// and maps
+//
+//	{{Nickname | default "-"}}
+func defaultValue(v interface{}, args ...string) (interface{}, error) {
+	if len(args) != 1 {
+		return nil, ErrFormatterArgs
+	}
+	if !isTrue(reflect.ValueOf(v)) {
+		return args[0], nil
+	}
+	return v, nil
+}
+
+// date formats a time.Time with a Go layout, 2006-01-02 by default
+//
+//	{{Born | date "2006/01/02 15:04"}}
+func date(v interface{}, args ...string) (interface{}, error) {
+	layout := "2006-01-02"
+	switch len(args) {
+	case 0:
+	case 1:
+		layout = args[0]
+	default:
+		return nil, ErrFormatterArgs
+	}
+
+	switch t := v.(type) {
+	case time.Time:
+		return t.Format(layout), nil
+	case *time.Time:
+		if t == nil {
+			return "", nil
+		}
+		return t.Format(layout), nil
+	}
+	return nil, ErrFormatterValue
+}
+
+// jalaliDate formats a time.Time as a Jalali date, yyyy/MM/dd by default, see
+// jalali.Format for the layout, the zero time is formatted as an empty string
+//
+//	{{Born | jalali "EEEE d MMMM yyyy"}}
+func jalaliDate(v interface{}, args ...string) (interface{}, error) {
+	layout := "yyyy/MM/dd"
+	switch len(args) {
+	case 0:
+	case 1:
+		layout = args[0]
+	default:
+		return nil, ErrFormatterArgs
+	}
+
+	var t time.Time
+	switch tv := v.(type) {
+	case time.Time:
+		t = tv
+	case *time.Time:
+		if tv == nil {
+			return "", nil
+		}
+		t = *tv
+	default:
+		return nil, ErrFormatterValue
+	}
+	// unset times are left empty, years the calendar doesn't cover fail
+	// rather than turning into nonsense dates
+	if t.IsZero() {
+		return "", nil
+	}
+	if _, err := jalali.Convert(t); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFormatterValue, err)
+	}
+	return jalali.Format(t, layout), nil
+}
+
+// number formats a number with thousands separators and optionally a fixed
+// number of decimals, the separators and digits are those of the locale the
+// template is executed with
+//
+//	{{Total | number}} {{Price | number 2}}
+func number(v interface{}, args ...string) (interface{}, error) {
+	decimals := -1
+	switch len(args) {
+	case 0:
+	case 1:
+		d, err := strconv.Atoi(args[0])
+		if err != nil || d < 0 {
+			return nil, ErrFormatterArgs
+		}
+		decimals = d
+	default:
+		return nil, ErrFormatterArgs
+	}
+
+	n, err := formatNumber(v, decimals)
+	if err != nil {
+		return nil, err
+	}
+	return Number{Value: n}, nil
+}
+
+// words spells out a number, in the language of the given tag or otherwise of
+// the locale the template is executed with
+//
+//	{{Total | words}} {{Total | words "fa"}}
+func words(v interface{}, args ...string) (interface{}, error) {
+	if len(args) > 1 {
+		return nil, ErrFormatterArgs
+	}
+	n, err := formatNumber(v, -1)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 1 {
+		return SpellNumber(n, args[0])
+	}
+	if _, err := SpellNumber(n, English.Tag); err != nil {
+		return nil, err
+	}
+	return SpelledNumber{Value: n}, nil
+}
+
+// truncate cuts the value to the given number of characters, appending the
+// optional suffix when it does
+//
+//	{{Description | truncate 20 "..."}}
+func truncate(v interface{}, args ...string) (interface{}, error) {
+	if len(args) != 1 && len(args) != 2 {
+		return nil, ErrFormatterArgs
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 0 {
+		return nil, ErrFormatterArgs
+	}
+
+	s := []rune(toString(v))
+	if len(s) <= n {
+		return string(s), nil
+	}
+	if len(args) == 2 {
+		return string(s[:n]) + args[1], nil
+	}
+	return string(s[:n]), nil
+}
+
+var decimalPattern = regexp.MustCompile(`^[-+]?\d+(\.\d+)?$`)
+
+// formatNumber converts numbers and numeric strings to a plain decimal
+// representation, decimals of -1 keeps the significant ones
+func formatNumber(v interface{}, decimals int) (string, error) {
+	if n, ok := numberString(v, decimals); ok {
+		return n, nil
+	}
+
+	s, ok := v.(string)
+	if !ok {
+		return "", ErrFormatterValue
+	}
+	if plain := decimalPattern.FindStringSubmatch(s); plain != nil {
+		// plain decimals such as IDs and amounts can have more digits than
+		// a float64 holds
+		r, _ := new(big.Rat).SetString(s)
+		if decimals >= 0 {
+			return r.FloatString(decimals), nil
+		}
+		if plain[1] == "" {
+			return r.Num().String(), nil
+		}
+		n := strings.TrimRight(r.FloatString(len(plain[1])-1), "0")
+		return strings.TrimSuffix(n, "."), nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return "", ErrFormatterValue
+	}
+	return strconv.FormatFloat(f, 'f', decimals, 64), nil
+}
+
+// groupDigits inserts the separator between every three digits of the integer
+// part of a plain decimal number and replaces its decimal point
+func groupDigits(n string, sep string, point string) string {
+	sign := ""
+	if strings.HasPrefix(n, "-") {
+		sign, n = "-", n[1:]
+	}
+	integer, fraction, hasFraction := strings.Cut(n, ".")
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, d := range integer {
+		if i != 0 && (len(integer)-i)%3 == 0 {
+			b.WriteString(sep)
+		}
+		b.WriteRune(d)
+	}
+	if hasFraction {
+		b.WriteString(point)
+		b.WriteString(fraction)
+	}
+	return b.String()
+}
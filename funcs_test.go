@@ -0,0 +1,130 @@
+package samdoc
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParsePipeline(t *testing.T) {
+	Convey("Test Pipeline: Parse", t, func() {
+		pl, err := ParsePipeline(` Invoice.Total | number 2 | default "no | total" `)
+		So(err, ShouldBeNil)
+		So(pl.Query, ShouldResemble, FieldQuery{"Invoice", "Total"})
+		So(pl.Calls, ShouldResemble, []Call{
+			{Name: "number", Args: []string{"2"}},
+			{Name: "default", Args: []string{"no | total"}},
+		})
+
+		pl, err = ParsePipeline(`Born | date “2006/01/02”`)
+		So(err, ShouldBeNil)
+		So(pl.Calls, ShouldResemble, []Call{{Name: "date", Args: []string{"2006/01/02"}}})
+
+		pl, err = ParsePipeline(`Name | default ""`)
+		So(err, ShouldBeNil)
+		So(pl.Calls, ShouldResemble, []Call{{Name: "default", Args: []string{""}}})
+
+		_, err = ParsePipeline(`Name | default "-`)
+		So(errors.Is(err, ErrInvalidPipeline), ShouldBeTrue)
+		_, err = ParsePipeline(`Name | `)
+		So(errors.Is(err, ErrInvalidPipeline), ShouldBeTrue)
+		_, err = ParsePipeline(` | upper`)
+		So(errors.Is(err, ErrInvalidPipeline), ShouldBeTrue)
+	})
+}
+
+func TestFormatters(t *testing.T) {
+	type Invoice struct {
+		Customer    string
+		Nickname    string
+		Total       int
+		Price       float64
+		Description string
+		Account     string
+		Issued      time.Time
+	}
+	var invoice = Invoice{
+		Customer:    " saman koushki ",
+		Total:       -1234567,
+		Price:       1234.5,
+		Description: "a very long description",
+		Account:     "+0012345678901234567890123.4560",
+		Issued:      time.Date(2023, 3, 21, 10, 30, 0, 0, time.UTC),
+	}
+
+	Convey("Test Formatters: Builtins", t, func() {
+		m, err := NewStructure(&invoice)
+		So(err, ShouldBeNil)
+		for text, expected := range map[string]string{
+			"Customer | trim | upper":              "SAMAN KOUSHKI",
+			"Customer | lower":                     " saman koushki ",
+			"Nickname | default \"-\"":             "-",
+			"Customer | default \"-\"":             " saman koushki ",
+			"Total | number":                       "-1,234,567",
+			"Total | number 2":                     "-1,234,567.00",
+			"Price | number":                       "1,234.5",
+			"Price | number 2":                     "1,234.50",
+			"Account | number":                     "12,345,678,901,234,567,890,123.456",
+			"Account | number 2":                   "12,345,678,901,234,567,890,123.46",
+			"Issued | date":                        "2023-03-21",
+			"Issued | date \"15:04 02/01\"":        "10:30 21/03",
+			"Issued | jalali":                      "1402/01/01",
+			"Issued | jalali \"EEEE d MMMM yyyy\"": "سه‌شنبه 1 فروردین 1402",
+			"Description | truncate 6":             "a very",
+			"Description | truncate 6 ...":         "a very...",
+			"Description | truncate 60 ..":         "a very long description",
+		} {
+			pl, err := ParsePipeline(text)
+			So(err, ShouldBeNil)
+			val, err := m.Execute(pl, nil)
+			So(err, ShouldBeNil)
+			So(val, ShouldEqual, expected)
+		}
+	})
+
+	Convey("Test Formatters: Errors And Custom Funcs", t, func() {
+		m, err := NewStructure(&invoice)
+		So(err, ShouldBeNil)
+
+		pl, _ := ParsePipeline("Customer | date")
+		_, err = m.Execute(pl, nil)
+		So(errors.Is(err, ErrFormatterValue), ShouldBeTrue)
+
+		pl, _ = ParsePipeline("Issued | jalali")
+		zero, _ := NewStructure(&Invoice{})
+		val, err := zero.Execute(pl, nil)
+		So(err, ShouldBeNil)
+		So(val, ShouldEqual, "")
+		far, _ := NewStructure(&Invoice{Issued: time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC)})
+		_, err = far.Execute(pl, nil)
+		So(errors.Is(err, ErrFormatterValue), ShouldBeTrue)
+
+		pl, _ = ParsePipeline("Total | number two")
+		_, err = m.Execute(pl, nil)
+		So(errors.Is(err, ErrFormatterArgs), ShouldBeTrue)
+
+		pl, _ = ParsePipeline("Total | currency IRR")
+		_, err = m.Execute(pl, nil)
+		So(errors.Is(err, ErrUnknownFormatter), ShouldBeTrue)
+
+		funcs := FuncMap{
+			"currency": func(v interface{}, args ...string) (interface{}, error) {
+				return toString(v) + " " + strings.Join(args, " "), nil
+			},
+			"upper": func(v interface{}, _ ...string) (interface{}, error) {
+				return "overridden", nil
+			},
+		}
+		val, err = m.Execute(pl, funcs)
+		So(err, ShouldBeNil)
+		So(val, ShouldEqual, "-1234567 IRR")
+
+		pl, _ = ParsePipeline("Customer | upper")
+		val, err = m.Execute(pl, funcs)
+		So(err, ShouldBeNil)
+		So(val, ShouldEqual, "overridden")
+	})
+}
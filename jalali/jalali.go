@@ -0,0 +1,344 @@
+// Package jalali converts dates between the Gregorian and the Jalali (Solar
+// Hijri) calendars and formats them, the conversion follows the algorithm of
+// Kazimierz M. Borkowski which is valid for the Jalali years -61 to 3177.
+package jalali
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidYear = errors.New("jalali year out of range")
+	ErrInvalidDate = errors.New("invalid jalali date")
+)
+
+type Month int
+
+const (
+	Farvardin Month = 1 + iota
+	Ordibehesht
+	Khordad
+	Tir
+	Mordad
+	Shahrivar
+	Mehr
+	Aban
+	Azar
+	Dey
+	Bahman
+	Esfand
+)
+
+var months = [...]string{
+	"فروردین", "اردیبهشت", "خرداد", "تیر", "مرداد", "شهریور",
+	"مهر", "آبان", "آذر", "دی", "بهمن", "اسفند",
+}
+
+var latinMonths = [...]string{
+	"Farvardin", "Ordibehesht", "Khordad", "Tir", "Mordad", "Shahrivar",
+	"Mehr", "Aban", "Azar", "Dey", "Bahman", "Esfand",
+}
+
+// String returns the Persian name of the month
+func (m Month) String() string {
+	if m < Farvardin || m > Esfand {
+		return "%!Month(" + strconv.Itoa(int(m)) + ")"
+	}
+	return months[m-1]
+}
+
+// Latin returns the transliterated name of the month
+func (m Month) Latin() string {
+	if m < Farvardin || m > Esfand {
+		return "%!Month(" + strconv.Itoa(int(m)) + ")"
+	}
+	return latinMonths[m-1]
+}
+
+var weekdays = [...]string{
+	time.Sunday:    "یک‌شنبه",
+	time.Monday:    "دوشنبه",
+	time.Tuesday:   "سه‌شنبه",
+	time.Wednesday: "چهارشنبه",
+	time.Thursday:  "پنج‌شنبه",
+	time.Friday:    "جمعه",
+	time.Saturday:  "شنبه",
+}
+
+var latinWeekdays = [...]string{
+	time.Sunday:    "Yekshanbeh",
+	time.Monday:    "Doshanbeh",
+	time.Tuesday:   "Seshanbeh",
+	time.Wednesday: "Chaharshanbeh",
+	time.Thursday:  "Panjshanbeh",
+	time.Friday:    "Jomeh",
+	time.Saturday:  "Shanbeh",
+}
+
+// Weekday returns the Persian name of the day of the week
+func Weekday(d time.Weekday) string {
+	return weekdays[d]
+}
+
+// Date is a day of the Jalali calendar
+type Date struct {
+	Year  int
+	Month Month
+	Day   int
+}
+
+// FromTime returns the Jalali date of t in its location
+func FromTime(t time.Time) Date {
+	gy, gm, gd := t.Date()
+	return fromJDN(gregorianToJDN(gy, int(gm), gd))
+}
+
+// Convert returns the Jalali date of t in its location, ErrInvalidYear when
+// the date is out of the range of the calendar
+func Convert(t time.Time) (Date, error) {
+	d := FromTime(t)
+	if _, _, _, err := calendar(d.Year); err != nil {
+		return Date{}, err
+	}
+	return d, nil
+}
+
+// NewDate validates and creates a Jalali date
+func NewDate(year int, month Month, day int) (Date, error) {
+	d := Date{Year: year, Month: month, Day: day}
+	if year < breaks[0] || year >= breaks[len(breaks)-1] {
+		return d, ErrInvalidYear
+	}
+	if month < Farvardin || month > Esfand || day < 1 || day > DaysIn(month, year) {
+		return d, ErrInvalidDate
+	}
+	return d, nil
+}
+
+// Time returns the time of the given clock on the date in loc
+func (d Date) Time(hour, min, sec, nsec int, loc *time.Location) time.Time {
+	gy, gm, gd := jdnToGregorian(d.jdn())
+	return time.Date(gy, time.Month(gm), gd, hour, min, sec, nsec, loc)
+}
+
+// Weekday returns the day of the week of the date
+func (d Date) Weekday() time.Weekday {
+	return time.Weekday((d.jdn() + 1) % 7)
+}
+
+// String returns the date in yyyy/MM/dd layout
+func (d Date) String() string {
+	return pad(d.Year, 4) + "/" + pad(int(d.Month), 2) + "/" + pad(d.Day, 2)
+}
+
+// IsLeap reports whether the Jalali year has 366 days
+func IsLeap(year int) bool {
+	leap, _, _, err := calendar(year)
+	return err == nil && leap == 0
+}
+
+// DaysIn returns the number of days of the month in the Jalali year
+func DaysIn(month Month, year int) int {
+	switch {
+	case month <= Shahrivar:
+		return 31
+	case month <= Bahman:
+		return 30
+	case IsLeap(year):
+		return 30
+	}
+	return 29
+}
+
+// Format formats t as a Jalali date, the layout is made of the patterns below
+// and any other character is copied, text between single quotes is copied as
+// is and two single quotes make a quote.
+//
+//	yyyy yy y       year
+//	MMMM MMM MM M   Persian and Latin name, number of month
+//	dd d            day of month
+//	EEEE EEE        Persian and Latin name of weekday
+//	HH H hh h       hour of day, hour of half day
+//	mm m ss s       minute, second
+//	a               ق.ظ or ب.ظ
+//
+// The zero time and times out of the range of the calendar are formatted as
+// empty strings.
+func Format(t time.Time, layout string) string {
+	d, err := Convert(t)
+	if err != nil || t.IsZero() {
+		return ""
+	}
+	var b strings.Builder
+	rs := []rune(layout)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		n := 1
+		for i+n < len(rs) && rs[i+n] == r {
+			n++
+		}
+
+		switch r {
+		case '\'':
+			if n > 1 {
+				b.WriteString(strings.Repeat("'", n/2))
+				i += n - n%2
+				continue
+			}
+			end := i + 1
+			for end < len(rs) && rs[end] != '\'' {
+				end++
+			}
+			b.WriteString(string(rs[i+1 : end]))
+			i = end + 1
+			continue
+		case 'y':
+			switch n {
+			case 2:
+				b.WriteString(pad(d.Year%100, 2))
+			default:
+				b.WriteString(pad(d.Year, n))
+			}
+		case 'M':
+			switch {
+			case n >= 4:
+				b.WriteString(d.Month.String())
+			case n == 3:
+				b.WriteString(d.Month.Latin())
+			default:
+				b.WriteString(pad(int(d.Month), n))
+			}
+		case 'd':
+			b.WriteString(pad(d.Day, n))
+		case 'E':
+			if n >= 4 {
+				b.WriteString(weekdays[t.Weekday()])
+			} else {
+				b.WriteString(latinWeekdays[t.Weekday()])
+			}
+		case 'H':
+			b.WriteString(pad(t.Hour(), n))
+		case 'h':
+			h := t.Hour() % 12
+			if h == 0 {
+				h = 12
+			}
+			b.WriteString(pad(h, n))
+		case 'm':
+			b.WriteString(pad(t.Minute(), n))
+		case 's':
+			b.WriteString(pad(t.Second(), n))
+		case 'a':
+			if t.Hour() < 12 {
+				b.WriteString("ق.ظ")
+			} else {
+				b.WriteString("ب.ظ")
+			}
+		default:
+			b.WriteString(string(rs[i : i+n]))
+		}
+		i += n
+	}
+	return b.String()
+}
+
+func pad(n int, width int) string {
+	s := strconv.Itoa(n)
+	if n < 0 {
+		return s
+	}
+	if len(s) < width {
+		s = strings.Repeat("0", width-len(s)) + s
+	}
+	return s
+}
+
+// breaks are the Jalali years starting a new 33 year cycle of leap years
+var breaks = [...]int{
+	-61, 9, 38, 199, 426, 686, 756, 818, 1111, 1181, 1210,
+	1635, 2060, 2097, 2192, 2262, 2324, 2394, 2456, 3178,
+}
+
+// calendar returns the number of years since the last leap year (0 to 4), the
+// Gregorian year in which the Jalali year begins and the day of March on
+// which it does.
+func calendar(jy int) (leap int, gy int, march int, err error) {
+	if jy < breaks[0] || jy >= breaks[len(breaks)-1] {
+		return 0, 0, 0, ErrInvalidYear
+	}
+
+	gy = jy + 621
+	leapJ := -14
+	jp := breaks[0]
+	jump := 0
+	for _, jm := range breaks[1:] {
+		jump = jm - jp
+		if jy < jm {
+			break
+		}
+		leapJ += jump/33*8 + jump%33/4
+		jp = jm
+	}
+	n := jy - jp
+
+	leapJ += n/33*8 + (n%33+3)/4
+	if jump%33 == 4 && jump-n == 4 {
+		leapJ++
+	}
+	leapG := gy/4 - (gy/100+1)*3/4 - 150
+	march = 20 + leapJ - leapG
+
+	if jump-n < 6 {
+		n = n - jump + (jump+4)/33*33
+	}
+	leap = ((n+1)%33 - 1) % 4
+	if leap == -1 {
+		leap = 4
+	}
+	return leap, gy, march, nil
+}
+
+// jdn returns the Julian day number of the date
+func (d Date) jdn() int {
+	_, gy, march, _ := calendar(d.Year)
+	m := int(d.Month)
+	return gregorianToJDN(gy, 3, march) + (m-1)*31 - m/7*(m-7) + d.Day - 1
+}
+
+func fromJDN(jdn int) Date {
+	gy, _, _ := jdnToGregorian(jdn)
+	jy := gy - 621
+	leap, _, march, _ := calendar(jy)
+	k := jdn - gregorianToJDN(gy, 3, march)
+	if k >= 0 {
+		if k <= 185 {
+			return Date{Year: jy, Month: Month(1 + k/31), Day: k%31 + 1}
+		}
+		k -= 186
+	} else {
+		jy--
+		k += 179
+		if leap == 1 {
+			k++
+		}
+	}
+	return Date{Year: jy, Month: Month(7 + k/30), Day: k%30 + 1}
+}
+
+func gregorianToJDN(gy, gm, gd int) int {
+	d := (gy+(gm-8)/6+100100)*1461/4 + (153*((gm+9)%12)+2)/5 + gd - 34840408
+	return d - (gy+100100+(gm-8)/6)/100*3/4 + 752
+}
+
+func jdnToGregorian(jdn int) (int, int, int) {
+	j := 4*jdn + 139361631
+	j += (4*jdn+183187720)/146097*3/4*4 - 3908
+	i := j%1461/4*5 + 308
+	gd := i%153/5 + 1
+	gm := i/153%12 + 1
+	gy := j/1461 - 100100 + (8-gm)/6
+	return gy, gm, gd
+}
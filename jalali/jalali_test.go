@@ -0,0 +1,84 @@
+package jalali
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestConversion(t *testing.T) {
+	Convey("Test Jalali: Gregorian To Jalali", t, func() {
+		for g, j := range map[time.Time]Date{
+			time.Date(2023, 3, 21, 0, 0, 0, 0, time.UTC):  {1402, Farvardin, 1},
+			time.Date(2023, 3, 20, 0, 0, 0, 0, time.UTC):  {1401, Esfand, 29},
+			time.Date(2025, 3, 20, 0, 0, 0, 0, time.UTC):  {1403, Esfand, 30},
+			time.Date(1979, 2, 11, 0, 0, 0, 0, time.UTC):  {1357, Bahman, 22},
+			time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC):   {1378, Dey, 11},
+			time.Date(2023, 9, 23, 0, 0, 0, 0, time.UTC):  {1402, Mehr, 1},
+			time.Date(2023, 9, 22, 0, 0, 0, 0, time.UTC):  {1402, Shahrivar, 31},
+			time.Date(1900, 12, 31, 0, 0, 0, 0, time.UTC): {1279, Dey, 10},
+		} {
+			So(FromTime(g), ShouldResemble, j)
+			So(j.Time(0, 0, 0, 0, time.UTC), ShouldEqual, g)
+			So(j.Weekday(), ShouldEqual, g.Weekday())
+		}
+	})
+
+	Convey("Test Jalali: Round Trip", t, func() {
+		day := time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)
+		prev := FromTime(day.AddDate(0, 0, -1))
+		for i := 0; i < 366*60; i++ {
+			d := FromTime(day)
+			So(d.Time(0, 0, 0, 0, time.UTC), ShouldEqual, day)
+			if d.Day == 1 {
+				So(prev.Day, ShouldEqual, DaysIn(prev.Month, prev.Year))
+			} else {
+				So(d.Day, ShouldEqual, prev.Day+1)
+			}
+			prev = d
+			day = day.AddDate(0, 0, 1)
+		}
+	})
+
+	Convey("Test Jalali: Leap Years", t, func() {
+		So(IsLeap(1399), ShouldBeTrue)
+		So(IsLeap(1400), ShouldBeFalse)
+		So(IsLeap(1403), ShouldBeTrue)
+		So(IsLeap(1404), ShouldBeFalse)
+		So(DaysIn(Esfand, 1403), ShouldEqual, 30)
+		So(DaysIn(Esfand, 1402), ShouldEqual, 29)
+
+		_, err := NewDate(1402, Esfand, 30)
+		So(err, ShouldEqual, ErrInvalidDate)
+		_, err = NewDate(1403, Esfand, 30)
+		So(err, ShouldBeNil)
+		_, err = NewDate(3200, Farvardin, 1)
+		So(err, ShouldEqual, ErrInvalidYear)
+	})
+}
+
+func TestFormat(t *testing.T) {
+	Convey("Test Jalali: Format", t, func() {
+		tm := time.Date(2023, 3, 21, 16, 5, 9, 0, time.UTC)
+		for layout, expected := range map[string]string{
+			"yyyy/MM/dd":             "1402/01/01",
+			"yy-M-d":                 "02-1-1",
+			"EEEE d MMMM yyyy":       "سه‌شنبه 1 فروردین 1402",
+			"EEE, d MMM yyyy":        "Seshanbeh, 1 Farvardin 1402",
+			"HH:mm:ss":               "16:05:09",
+			"h:m a":                  "4:5 ب.ظ",
+			"'day' d 'of' MMM, ''yy": "day 1 of Farvardin, '02",
+		} {
+			So(Format(tm, layout), ShouldEqual, expected)
+		}
+		So(Format(time.Time{}, "yyyy/MM/dd"), ShouldEqual, "")
+		So(Format(time.Date(4000, 1, 1, 0, 0, 0, 0, time.UTC), "yyyy"), ShouldEqual, "")
+		_, err := Convert(time.Date(4000, 1, 1, 0, 0, 0, 0, time.UTC))
+		So(err, ShouldEqual, ErrInvalidYear)
+
+		So(Date{1402, Tir, 9}.String(), ShouldEqual, "1402/04/09")
+		So(Mehr.String(), ShouldEqual, "مهر")
+		So(Weekday(time.Friday), ShouldEqual, "جمعه")
+	})
+}
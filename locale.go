@@ -0,0 +1,184 @@
+package samdoc
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Locale describes the digits and separators numbers are rendered with
+type Locale struct {
+	Tag     string
+	Digits  [10]rune
+	Group   string
+	Decimal string
+}
+
+var (
+	English = &Locale{
+		Tag:     "en",
+		Digits:  [10]rune{'0', '1', '2', '3', '4', '5', '6', '7', '8', '9'},
+		Group:   ",",
+		Decimal: ".",
+	}
+	Persian = &Locale{
+		Tag:     "fa",
+		Digits:  [10]rune{'۰', '۱', '۲', '۳', '۴', '۵', '۶', '۷', '۸', '۹'},
+		Group:   "٬",
+		Decimal: "٫",
+	}
+	Arabic = &Locale{
+		Tag:     "ar",
+		Digits:  [10]rune{'٠', '١', '٢', '٣', '٤', '٥', '٦', '٧', '٨', '٩'},
+		Group:   "٬",
+		Decimal: "٫",
+	}
+)
+
+var locales = map[string]*Locale{
+	English.Tag: English,
+	Persian.Tag: Persian,
+	Arabic.Tag:  Arabic,
+}
+
+// LookupLocale returns the locale of a language tag such as fa or fa-IR
+func LookupLocale(tag string) (*Locale, bool) {
+	tag = strings.ToLower(tag)
+	if i := strings.IndexAny(tag, "-_"); i != -1 {
+		tag = tag[:i]
+	}
+	l, ok := locales[tag]
+	return l, ok
+}
+
+// Transliterate replaces the Latin, Persian and Arabic-Indic digits of s with
+// the digits of the locale
+func (l *Locale) Transliterate(s string) string {
+	return strings.Map(func(r rune) rune {
+		if d := digitValue(r); d != -1 {
+			return l.Digits[d]
+		}
+		return r
+	}, s)
+}
+
+// Sprint converts the value to string, numbers are rendered with the decimal
+// separator and digits of the locale and the digits of anything else are
+// transliterated. A nil locale leaves the value as is.
+func (l *Locale) Sprint(v interface{}) string {
+	if l == nil {
+		return toString(v)
+	}
+	if n, ok := v.(Number); ok {
+		return l.Transliterate(groupDigits(n.Value, l.Group, l.Decimal))
+	}
+	if n, ok := v.(SpelledNumber); ok {
+		if s, err := SpellNumber(n.Value, l.Tag); err == nil {
+			return s
+		}
+		return l.Transliterate(n.String())
+	}
+	if n, ok := numberString(v, -1); ok {
+		return l.Transliterate(strings.Replace(n, ".", l.Decimal, 1))
+	}
+	return l.Transliterate(toString(v))
+}
+
+// Number is a number formatted by the number formatter, Value is its plain
+// decimal representation. It's rendered with thousands separators and the
+// digits of the locale a template is executed with.
+type Number struct {
+	Value string
+}
+
+func (n Number) String() string {
+	return groupDigits(n.Value, English.Group, English.Decimal)
+}
+
+func digitValue(r rune) int {
+	switch {
+	case r >= '0' && r <= '9':
+		return int(r - '0')
+	case r >= '۰' && r <= '۹':
+		return int(r - '۰')
+	case r >= '٠' && r <= '٩':
+		return int(r - '٠')
+	}
+	return -1
+}
+
+// numberString returns the plain decimal representation of integers, floats
+// and big numbers, decimals of -1 keeps the significant ones
+func numberString(v interface{}, decimals int) (string, bool) {
+	switch n := v.(type) {
+	case *big.Int:
+		if n == nil {
+			return "", false
+		}
+		return withDecimals(n.String(), decimals), true
+	case big.Int:
+		return withDecimals(n.String(), decimals), true
+	case *big.Float:
+		if n == nil {
+			return "", false
+		}
+		return n.Text('f', decimals), true
+	case big.Float:
+		return n.Text('f', decimals), true
+	case *big.Rat:
+		if n == nil {
+			return "", false
+		}
+		return ratString(n, decimals), true
+	case big.Rat:
+		return ratString(&n, decimals), true
+	case Number:
+		return n.Value, true
+	case json.Number:
+		r, ok := new(big.Rat).SetString(string(n))
+		if !ok {
+			return "", false
+		}
+		return ratString(r, decimals), true
+	}
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return "", false
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return withDecimals(strconv.FormatInt(rv.Int(), 10), decimals), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return withDecimals(strconv.FormatUint(rv.Uint(), 10), decimals), true
+	case reflect.Float32:
+		return strconv.FormatFloat(rv.Float(), 'f', decimals, 32), true
+	case reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'f', decimals, 64), true
+	}
+	return "", false
+}
+
+func withDecimals(integer string, decimals int) string {
+	if decimals > 0 {
+		return integer + "." + strings.Repeat("0", decimals)
+	}
+	return integer
+}
+
+// ratString formats a big.Rat, without fixed decimals it's cut after 20
+func ratString(r *big.Rat, decimals int) string {
+	if decimals >= 0 {
+		return r.FloatString(decimals)
+	}
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	s := strings.TrimRight(r.FloatString(20), "0")
+	return strings.TrimSuffix(s, ".")
+}
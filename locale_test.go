@@ -0,0 +1,84 @@
+package samdoc
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLocale(t *testing.T) {
+	Convey("Test Locale: Lookup", t, func() {
+		l, ok := LookupLocale("fa-IR")
+		So(ok, ShouldBeTrue)
+		So(l, ShouldEqual, Persian)
+		_, ok = LookupLocale("xx")
+		So(ok, ShouldBeFalse)
+	})
+
+	Convey("Test Locale: Sprint", t, func() {
+		So(Persian.Sprint(1234567), ShouldEqual, "۱۲۳۴۵۶۷")
+		So(Persian.Sprint(-12.5), ShouldEqual, "-۱۲٫۵")
+		So(Persian.Sprint(float32(1.1)), ShouldEqual, "۱٫۱")
+		So(Persian.Sprint(Number{Value: "-1234567.50"}), ShouldEqual, "-۱٬۲۳۴٬۵۶۷٫۵۰")
+		So(Arabic.Sprint(Number{Value: "1234"}), ShouldEqual, "١٬٢٣٤")
+		So(English.Sprint("۰۹۱۲ ٣٤٥"), ShouldEqual, "0912 345")
+		So(Persian.Sprint("room 12"), ShouldEqual, "room ۱۲")
+
+		n, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+		So(Persian.Sprint(n), ShouldEqual, "۱۲۳۴۵۶۷۸۹۰۱۲۳۴۵۶۷۸۹۰۱۲۳۴۵۶۷۸۹۰")
+		So(English.Sprint(big.NewRat(1, 4)), ShouldEqual, "0.25")
+		So(English.Sprint(big.NewFloat(2.5)), ShouldEqual, "2.5")
+
+		var l *Locale
+		So(l.Sprint(12.5), ShouldEqual, "12.5")
+	})
+
+	Convey("Test Locale: Number Formatter With Big Numbers", t, func() {
+		type Account struct {
+			Balance *big.Int
+			Rate    *big.Rat
+		}
+		b, _ := new(big.Int).SetString("98765432109876543210", 10)
+		m, err := NewStructure(&Account{Balance: b, Rate: big.NewRat(3, 8)})
+		So(err, ShouldBeNil)
+
+		pl, _ := ParsePipeline("Balance | number")
+		val, err := m.Evaluate(pl, nil)
+		So(err, ShouldBeNil)
+		So(Persian.Sprint(val), ShouldEqual, "۹۸٬۷۶۵٬۴۳۲٬۱۰۹٬۸۷۶٬۵۴۳٬۲۱۰")
+		So(English.Sprint(val), ShouldEqual, "98,765,432,109,876,543,210")
+
+		pl, _ = ParsePipeline("Rate | number 2")
+		val, err = m.Execute(pl, nil)
+		So(err, ShouldBeNil)
+		So(val, ShouldEqual, "0.38")
+	})
+}
+
+func TestJSONNumbers(t *testing.T) {
+	Convey("Test Locale: JSON Numbers", t, func() {
+		strct, err := NewStructure(map[string]interface{}{
+			"Total": json.Number("12345678901234567890"),
+			"Rate":  json.Number("1.5e1"),
+			"Zero":  json.Number("0"),
+		})
+		So(err, ShouldBeNil)
+
+		eval := func(text string) interface{} {
+			pl, err := ParsePipeline(text)
+			So(err, ShouldBeNil)
+			v, err := strct.Evaluate(pl, nil)
+			So(err, ShouldBeNil)
+			return v
+		}
+		So(Persian.Sprint(eval("Total | number")), ShouldEqual, "۱۲٬۳۴۵٬۶۷۸٬۹۰۱٬۲۳۴٬۵۶۷٬۸۹۰")
+		So(English.Sprint(eval("Rate | number 2")), ShouldEqual, "15.00")
+		So(English.Sprint(eval("Rate")), ShouldEqual, "15")
+
+		ok, err := strct.Truth(NewFieldQuery("Zero"))
+		So(err, ShouldBeNil)
+		So(ok, ShouldBeFalse)
+	})
+}
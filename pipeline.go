@@ -0,0 +1,161 @@
+package samdoc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrInvalidPipeline  = errors.New("invalid pipeline")
+	ErrUnknownFormatter = errors.New("unknown formatter")
+)
+
+// Pipeline is a parsed placeholder, the field addressed by Query is passed
+// through every formatter of Calls in order:
+//
+//	Invoice.Total | number 2 | default "-"
+type Pipeline struct {
+	Query FieldQuery
+	Calls []Call
+}
+
+// Call is a formatter of a pipeline along with its arguments
+type Call struct {
+	Name string
+	Args []string
+}
+
+// ParsePipeline parses the text of a placeholder, arguments are either bare
+// words or quoted with straight or typographic double quotes.
+func ParsePipeline(text string) (Pipeline, error) {
+	var pl Pipeline
+	stages, err := splitPipeline(text)
+	if err != nil {
+		return pl, err
+	}
+
+	qry := strings.TrimSpace(stages[0])
+	if qry == "" {
+		return pl, fmt.Errorf("%w: missing field in %q", ErrInvalidPipeline, text)
+	}
+	pl.Query = NewFieldQuery(qry)
+	for _, stage := range stages[1:] {
+		words, err := splitWords(stage)
+		if err != nil {
+			return pl, err
+		}
+		if len(words) == 0 {
+			return pl, fmt.Errorf("%w: empty formatter in %q", ErrInvalidPipeline, text)
+		}
+		pl.Calls = append(pl.Calls, Call{Name: words[0], Args: words[1:]})
+	}
+	return pl, nil
+}
+
+// Execute resolves the query of the pipeline and passes the value through its
+// formatters, formatters are looked up in funcs first and then the builtins.
+func (s *Structure) Execute(pl Pipeline, funcs FuncMap) (string, error) {
+	val, err := s.Evaluate(pl, funcs)
+	if err != nil {
+		return "", err
+	}
+	return toString(val), nil
+}
+
+// Evaluate is like Execute but returns the resulting value rather than its
+// string representation, see Locale.Sprint
+func (s *Structure) Evaluate(pl Pipeline, funcs FuncMap) (interface{}, error) {
+	v, f, err := s.resolve(pl.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	val := valueInterface(v)
+	if text, ok := f.empty(v); ok {
+		val = text
+	}
+	for _, call := range pl.Calls {
+		f, ok := funcs.Lookup(call.Name)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownFormatter, call.Name)
+		}
+		val, err = f(val, call.Args...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", call.Name, err)
+		}
+	}
+	if err := s.checkNil(val); err != nil {
+		return nil, err
+	}
+	return val, nil
+}
+
+var quotes = map[rune]rune{
+	'"': '"',
+	'“': '”',
+	'„': '“',
+	'«': '»',
+}
+
+// splitPipeline splits the text on the pipes which are not quoted
+func splitPipeline(text string) ([]string, error) {
+	var stages []string
+	var closing rune
+	start := 0
+	for i, r := range text {
+		switch {
+		case closing != 0:
+			if r == closing {
+				closing = 0
+			}
+		case quotes[r] != 0:
+			closing = quotes[r]
+		case r == '|':
+			stages = append(stages, text[start:i])
+			start = i + 1
+		}
+	}
+	if closing != 0 {
+		return nil, fmt.Errorf("%w: unterminated quote in %q", ErrInvalidPipeline, text)
+	}
+	return append(stages, text[start:]), nil
+}
+
+// splitWords splits a stage of a pipeline on spaces which are not quoted and
+// removes the quotes
+func splitWords(stage string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	var closing rune
+	quoted := false
+	for _, r := range stage {
+		switch {
+		case closing != 0:
+			if r == closing {
+				closing = 0
+				continue
+			}
+			word.WriteRune(r)
+		case quotes[r] != 0:
+			closing = quotes[r]
+			quoted = true
+		case unicode.IsSpace(r):
+			if word.Len() != 0 || quoted {
+				words = append(words, word.String())
+				word.Reset()
+				quoted = false
+			}
+		default:
+			word.WriteRune(r)
+		}
+	}
+	if closing != 0 {
+		return nil, fmt.Errorf("%w: unterminated quote in %q", ErrInvalidPipeline, stage)
+	}
+	if word.Len() != 0 || quoted {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
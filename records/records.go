@@ -0,0 +1,296 @@
+// Package records reads the records of data files, such as a spreadsheet
+// exported as CSV, as map models templates can be executed against
+package records
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrNotObject       = errors.New("record is not a JSON object")
+	ErrDuplicateColumn = errors.New("duplicate column")
+	ErrColumnConflict  = errors.New("column conflicts with a dotted column")
+	ErrTrailingData    = errors.New("data after the record")
+	ErrInvalidBool     = errors.New("invalid boolean")
+)
+
+// bom is the byte order mark spreadsheets start UTF-8 files with
+const bom = "\ufeff"
+
+// Record is a single record of a data source, nested objects and dotted CSV
+// columns are nested records
+type Record = map[string]interface{}
+
+// Reader reads the records of a data source one at a time, Read returns
+// io.EOF after the last one
+type Reader interface {
+	Read() (Record, error)
+}
+
+// Send sends every record of the reader to the channel and closes it, it
+// returns the first error other than io.EOF
+//
+//	models := make(chan interface{})
+//	go func() { errc <- records.Send(ctx, r, models) }()
+//	err := tmp.ExecuteBatch(ctx, models, sink, docx.BatchOptions{})
+func Send(ctx context.Context, r Reader, models chan<- interface{}) error {
+	defer close(models)
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		select {
+		case models <- rec:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// ReadAll returns all the records of the reader
+func ReadAll(r Reader) ([]Record, error) {
+	var recs []Record
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return recs, nil
+		}
+		if err != nil {
+			return recs, err
+		}
+		recs = append(recs, rec)
+	}
+}
+
+// CSVReader reads the rows of a CSV file with a header row, the header names
+// the fields of the records and a dotted name such as Customer.Name makes a
+// nested record. Values are kept as strings, the number formatter parses them,
+// except for the columns Bools names.
+type CSVReader struct {
+	r      *csv.Reader
+	header [][]string
+	bools  map[string]bool
+	isBool []bool
+}
+
+// NewCSVReader creates a CSVReader, r can be configured with a different
+// separator and such before the first Read
+func NewCSVReader(r io.Reader) *CSVReader {
+	return &CSVReader{r: csv.NewReader(r)}
+}
+
+// CSV returns the underlying csv.Reader
+func (c *CSVReader) CSV() *csv.Reader {
+	return c.r
+}
+
+// Bools reads the cells of the columns as bool values, so a column such as
+// Paid holding FALSE is false to {{if Paid}}. It has to be called before the
+// first Read.
+func (c *CSVReader) Bools(columns ...string) *CSVReader {
+	if c.bools == nil {
+		c.bools = make(map[string]bool, len(columns))
+	}
+	for _, name := range columns {
+		c.bools[name] = true
+	}
+	return c
+}
+
+func (c *CSVReader) Read() (Record, error) {
+	if c.header == nil {
+		header, err := c.r.Read()
+		if err != nil {
+			return nil, err
+		}
+		header[0] = strings.TrimPrefix(header[0], bom)
+		seen := make(map[string]bool, len(header))
+		for i, name := range header {
+			name = strings.TrimSpace(name)
+			if seen[name] {
+				return nil, fmt.Errorf("%w: %q", ErrDuplicateColumn, name)
+			}
+			seen[name] = true
+			header[i] = name
+		}
+		// a column such as Customer can't hold a value and the nested record
+		// of Customer.City at once
+		for _, name := range header {
+			for i, r := range name {
+				if r == '.' && seen[name[:i]] {
+					return nil, fmt.Errorf("%w: %q and %q", ErrColumnConflict, name[:i], name)
+				}
+			}
+			c.header = append(c.header, strings.Split(name, "."))
+			c.isBool = append(c.isBool, c.bools[name])
+		}
+	}
+
+	row, err := c.r.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	rec := Record{}
+	for i, path := range c.header {
+		if i >= len(row) {
+			continue
+		}
+		if !c.isBool[i] {
+			set(rec, path, row[i])
+			continue
+		}
+		b, ok := parseBool(row[i])
+		if !ok {
+			line, _ := c.r.FieldPos(i)
+			return nil, fmt.Errorf("line %d: %w: %q in %s", line, ErrInvalidBool, row[i], strings.Join(path, "."))
+		}
+		set(rec, path, b)
+	}
+	return rec, nil
+}
+
+// parseBool parses the boolean cells spreadsheets and forms export
+func parseBool(s string) (bool, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "0", "false", "no", "off":
+		return false, true
+	case "1", "true", "yes", "on":
+		return true, true
+	}
+	return false, false
+}
+
+// set sets the value at the path of the record, creating nested records
+func set(rec Record, path []string, val interface{}) {
+	for _, key := range path[:len(path)-1] {
+		nested, ok := rec[key].(Record)
+		if !ok {
+			nested = Record{}
+			rec[key] = nested
+		}
+		rec = nested
+	}
+	rec[path[len(path)-1]] = val
+}
+
+// JSONReader reads the objects of a JSON array, or of a stream of JSON
+// objects. Numbers are decoded as json.Number so they keep their precision.
+type JSONReader struct {
+	br    *bufio.Reader
+	dec   *json.Decoder
+	array bool
+	n     int
+}
+
+func NewJSONReader(r io.Reader) *JSONReader {
+	return &JSONReader{br: bufio.NewReader(r)}
+}
+
+func (j *JSONReader) Read() (Record, error) {
+	if j.dec == nil {
+		b, err := peekNonSpace(j.br)
+		if err != nil {
+			return nil, err
+		}
+		j.dec = json.NewDecoder(j.br)
+		j.dec.UseNumber()
+		if b == '[' {
+			j.array = true
+			if _, err := j.dec.Token(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if j.array && !j.dec.More() {
+		return nil, io.EOF
+	}
+	var v interface{}
+	err := j.dec.Decode(&v)
+	if err != nil {
+		if err != io.EOF {
+			err = fmt.Errorf("record %d: %w", j.n, err)
+		}
+		return nil, err
+	}
+	rec, ok := v.(Record)
+	if !ok {
+		return nil, fmt.Errorf("record %d: %w", j.n, ErrNotObject)
+	}
+	j.n++
+	return rec, nil
+}
+
+// peekNonSpace returns the first byte which isn't a space or a byte order
+// mark without consuming it
+func peekNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		r, _, err := br.ReadRune()
+		if err != nil {
+			return 0, err
+		}
+		if !unicode.IsSpace(r) && string(r) != bom {
+			return byte(r), br.UnreadRune()
+		}
+	}
+}
+
+// JSONLinesReader reads a JSON Lines file, an object per line, blank lines
+// are skipped
+type JSONLinesReader struct {
+	s    *bufio.Scanner
+	line int
+}
+
+func NewJSONLinesReader(r io.Reader) *JSONLinesReader {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 16*1024*1024)
+	return &JSONLinesReader{s: s}
+}
+
+func (j *JSONLinesReader) Read() (Record, error) {
+	for j.s.Scan() {
+		j.line++
+		line := bytes.TrimSpace(j.s.Bytes())
+		if j.line == 1 {
+			line = bytes.TrimPrefix(line, []byte(bom))
+		}
+		if len(line) == 0 {
+			continue
+		}
+
+		dec := json.NewDecoder(bytes.NewReader(line))
+		dec.UseNumber()
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			return nil, fmt.Errorf("line %d: %w", j.line, err)
+		}
+		// a line holds a single object, anything after it isn't a record
+		if err := dec.Decode(new(json.RawMessage)); err != io.EOF {
+			return nil, fmt.Errorf("line %d: %w", j.line, ErrTrailingData)
+		}
+		rec, ok := v.(Record)
+		if !ok {
+			return nil, fmt.Errorf("line %d: %w", j.line, ErrNotObject)
+		}
+		return rec, nil
+	}
+	if err := j.s.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
@@ -0,0 +1,110 @@
+package records
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCSV(t *testing.T) {
+	Convey("Test Records: CSV With Nested Columns", t, func() {
+		r := NewCSVReader(strings.NewReader("\ufeffName, Customer.City,Customer.Phone,Total\nSara,Tabriz,0912,1200000\n\"Ali, Jr\",Shiraz,,50\n"))
+		recs, err := ReadAll(r)
+		So(err, ShouldBeNil)
+		So(recs, ShouldResemble, []Record{
+			{"Name": "Sara", "Customer": Record{"City": "Tabriz", "Phone": "0912"}, "Total": "1200000"},
+			{"Name": "Ali, Jr", "Customer": Record{"City": "Shiraz", "Phone": ""}, "Total": "50"},
+		})
+	})
+
+	Convey("Test Records: CSV Separator And Errors", t, func() {
+		r := NewCSVReader(strings.NewReader("Name;Total\nSara;12\n"))
+		r.CSV().Comma = ';'
+		recs, err := ReadAll(r)
+		So(err, ShouldBeNil)
+		So(recs, ShouldResemble, []Record{{"Name": "Sara", "Total": "12"}})
+
+		_, err = NewCSVReader(strings.NewReader("Name,Name\na,b\n")).Read()
+		So(errors.Is(err, ErrDuplicateColumn), ShouldBeTrue)
+
+		_, err = NewCSVReader(strings.NewReader("Customer.City,Customer\na,b\n")).Read()
+		So(errors.Is(err, ErrColumnConflict), ShouldBeTrue)
+		_, err = NewCSVReader(strings.NewReader("Customer.Address,Customer.Address.City\na,b\n")).Read()
+		So(errors.Is(err, ErrColumnConflict), ShouldBeTrue)
+	})
+
+	Convey("Test Records: CSV Bools", t, func() {
+		r := NewCSVReader(strings.NewReader("Name,Paid,Order.Shipped\nSara,FALSE,Yes\nAli,,0\nReza,1,off\n")).Bools("Paid", "Order.Shipped")
+		recs, err := ReadAll(r)
+		So(err, ShouldBeNil)
+		So(recs, ShouldResemble, []Record{
+			{"Name": "Sara", "Paid": false, "Order": Record{"Shipped": true}},
+			{"Name": "Ali", "Paid": false, "Order": Record{"Shipped": false}},
+			{"Name": "Reza", "Paid": true, "Order": Record{"Shipped": false}},
+		})
+
+		recs, err = ReadAll(NewCSVReader(strings.NewReader("Name,Paid\nSara,FALSE\n")))
+		So(err, ShouldBeNil)
+		So(recs, ShouldResemble, []Record{{"Name": "Sara", "Paid": "FALSE"}})
+
+		_, err = ReadAll(NewCSVReader(strings.NewReader("Name,Paid\nSara,yes\nAli,maybe\n")).Bools("Paid"))
+		So(errors.Is(err, ErrInvalidBool), ShouldBeTrue)
+		So(err.Error(), ShouldStartWith, "line 3:")
+	})
+}
+
+func TestJSON(t *testing.T) {
+	Convey("Test Records: JSON Array And Stream", t, func() {
+		for _, data := range []string{
+			` [{"Name": "Sara", "Total": 12345678901234567890}, {"Name": "Ali", "Items": [{"Price": 2.5}]}]`,
+			`{"Name": "Sara", "Total": 12345678901234567890} {"Name": "Ali", "Items": [{"Price": 2.5}]}`,
+		} {
+			recs, err := ReadAll(NewJSONReader(strings.NewReader(data)))
+			So(err, ShouldBeNil)
+			So(recs, ShouldResemble, []Record{
+				{"Name": "Sara", "Total": json.Number("12345678901234567890")},
+				{"Name": "Ali", "Items": []interface{}{Record{"Price": json.Number("2.5")}}},
+			})
+		}
+
+		_, err := ReadAll(NewJSONReader(strings.NewReader(`[{"Name": "Sara"}, 12]`)))
+		So(errors.Is(err, ErrNotObject), ShouldBeTrue)
+		So(err.Error(), ShouldStartWith, "record 1:")
+	})
+
+	Convey("Test Records: JSON Lines", t, func() {
+		recs, err := ReadAll(NewJSONLinesReader(strings.NewReader("{\"Name\": \"Sara\"}\n\n  {\"Name\": \"Ali\"}\n")))
+		So(err, ShouldBeNil)
+		So(recs, ShouldResemble, []Record{{"Name": "Sara"}, {"Name": "Ali"}})
+
+		_, err = ReadAll(NewJSONLinesReader(strings.NewReader("{\"Name\": \"Sara\"}\n{\"Name\": \n")))
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldStartWith, "line 2:")
+
+		for _, data := range []string{"{\"a\":1} garbage\n", "{\"a\":1}}\n", "{\"a\":1} {\"a\":2}\n"} {
+			r := NewJSONLinesReader(strings.NewReader("{\"Name\": \"Sara\"}\n" + data))
+			_, err = ReadAll(r)
+			So(errors.Is(err, ErrTrailingData), ShouldBeTrue)
+			So(err.Error(), ShouldStartWith, "line 2:")
+		}
+	})
+
+	Convey("Test Records: Send", t, func() {
+		models := make(chan interface{})
+		errc := make(chan error, 1)
+		go func() {
+			errc <- Send(context.Background(), NewJSONLinesReader(strings.NewReader("{\"a\":1}\n{\"a\":2}\n")), models)
+		}()
+
+		var got []interface{}
+		for m := range models {
+			got = append(got, m)
+		}
+		So(<-errc, ShouldBeNil)
+		So(got, ShouldHaveLength, 2)
+	})
+}
@@ -0,0 +1,166 @@
+package samdoc
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// Schema resolves queries against the type of a model rather than a value, to
+// validate templates before executing them. Types only known at runtime, such
+// as the values of interfaces, resolve every query.
+type Schema struct {
+	t      reflect.Type
+	parent *Schema
+	cfg    *structureConfig
+}
+
+// NewSchema creates a Schema from a reflect.Type or a sample model, a nil
+// model resolves every query
+func NewSchema(model interface{}, opts ...StructureOption) (*Schema, error) {
+	cfg := new(structureConfig)
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if model == nil {
+		return &Schema{cfg: cfg}, nil
+	}
+
+	t, ok := model.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(model)
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct && t.Kind() != reflect.Map {
+		return nil, ErrModelMustBePointerToStruct
+	}
+	return &Schema{t: t, cfg: cfg}, nil
+}
+
+// Resolve returns the type of the value the query addresses, nil when it's
+// only known at runtime
+func (s *Schema) Resolve(qry FieldQuery) (reflect.Type, error) {
+	var t = s.t
+	var err error
+	for _, field := range qry {
+		if t == nil {
+			return nil, nil
+		}
+		t, err = s.fieldType(t, field)
+		if err != nil {
+			if s.parent != nil {
+				if pt, perr := s.parent.Resolve(qry); perr == nil {
+					return pt, nil
+				}
+			}
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// Items returns the schema of the elements of the slice or array addressed
+// by the query
+func (s *Schema) Items(qry FieldQuery) (*Schema, error) {
+	t, err := s.Resolve(qry)
+	if err != nil {
+		return nil, err
+	}
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() == reflect.Interface {
+		return &Schema{parent: s, cfg: s.cfg}, nil
+	}
+	if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+		return nil, ErrQueryFieldMustBeSlice
+	}
+	return &Schema{t: t.Elem(), parent: s, cfg: s.cfg}, nil
+}
+
+// Check reports whether the query of the pipeline resolves and its
+// formatters exist
+func (s *Schema) Check(pl Pipeline, funcs FuncMap) error {
+	var errs error
+	if _, err := s.Resolve(pl.Query); err != nil {
+		errs = err
+	}
+	for _, call := range pl.Calls {
+		if _, ok := funcs.Lookup(call.Name); !ok {
+			errs = errors.Join(errs, fmt.Errorf("%w: %s", ErrUnknownFormatter, call.Name))
+		}
+	}
+	return errs
+}
+
+// fieldType is the counterpart of Structure.getFieldByName for types
+func (s *Schema) fieldType(t reflect.Type, field string) (reflect.Type, error) {
+	ft, err := s.elementType(t, field)
+	if err == nil && ft != nil {
+		return ft, nil
+	}
+	// fields only known at runtime are still typed when a method matches
+	if mt, ok, merr := methodType(t, field); ok {
+		return mt, merr
+	}
+	return nil, err
+}
+
+func (s *Schema) elementType(t reflect.Type, field string) (reflect.Type, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Interface:
+		// methods of the interface are left to fieldType
+		if _, ok := t.MethodByName(field); ok {
+			return nil, ErrInvalidField
+		}
+		return nil, nil
+	case reflect.Struct:
+		fs := fieldsOf(t, s.cfg.jsonTags)
+		if f, ok := fs.lookup(field); ok {
+			return t.FieldByIndex(f.index).Type, nil
+		}
+		if len(fs.embedded) != 0 {
+			return nil, nil
+		}
+		return nil, ErrInvalidField
+	case reflect.Slice, reflect.Array:
+		if _, err := strconv.Atoi(field); err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidIndex, field)
+		}
+		return t.Elem(), nil
+	case reflect.Map:
+		if _, err := mapKey(t.Key(), field); err != nil {
+			return nil, err
+		}
+		return t.Elem(), nil
+	}
+	return nil, ErrQueryFieldMustBeStruct
+}
+
+// methodType returns the type of the value the exported method with the name
+// returns, ok is false when the type or its pointer has no such method
+func methodType(t reflect.Type, name string) (reflect.Type, bool, error) {
+	m, ok := t.MethodByName(name)
+	if !ok && t.Kind() != reflect.Ptr && t.Kind() != reflect.Interface {
+		m, ok = reflect.PtrTo(t).MethodByName(name)
+	}
+	if !ok {
+		return nil, false, nil
+	}
+
+	mt := m.Type
+	in := 1
+	if t.Kind() == reflect.Interface {
+		in = 0
+	}
+	if mt.NumIn() != in || mt.NumOut() == 0 || mt.NumOut() > 2 || mt.NumOut() == 2 && mt.Out(1) != errorType {
+		return nil, true, fmt.Errorf("%w: %s", ErrInvalidMethod, name)
+	}
+	return mt.Out(0), true, nil
+}
@@ -0,0 +1,62 @@
+package samdoc
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSchema(t *testing.T) {
+	Convey("Test Schema: Resolve", t, func() {
+		s, err := NewSchema(reflect.TypeOf(Employee{}))
+		So(err, ShouldBeNil)
+
+		for qry, expected := range map[string]reflect.Type{
+			"Name":          reflect.TypeOf(""),
+			"Cert.NIN":      reflect.TypeOf(NIN{}),
+			"Person.Cert":   reflect.TypeOf(&Cert{}),
+			"FullName":      reflect.TypeOf(""),
+			"Initials":      reflect.TypeOf(""),
+			"Manager.Title": reflect.TypeOf(""),
+			"Salary":        reflect.TypeOf(Salary(0)),
+			"Named.Name":    nil,
+			"Anything":      nil,
+		} {
+			typ, err := s.Resolve(NewFieldQuery(qry))
+			So(err, ShouldBeNil)
+			So(typ, ShouldEqual, expected)
+		}
+
+		s, err = NewSchema(&Person{})
+		So(err, ShouldBeNil)
+		_, err = s.Resolve(NewFieldQuery("Cert.Bron"))
+		So(err, ShouldEqual, ErrInvalidField)
+		_, err = s.Resolve(NewFieldQuery("Greet"))
+		So(errors.Is(err, ErrInvalidMethod), ShouldBeTrue)
+	})
+
+	Convey("Test Schema: Items And Check", t, func() {
+		type Order struct {
+			Customer string
+			Lines    []struct{ Product string }
+		}
+		s, err := NewSchema(&Order{})
+		So(err, ShouldBeNil)
+
+		items, err := s.Items(NewFieldQuery("Lines"))
+		So(err, ShouldBeNil)
+		_, err = items.Resolve(NewFieldQuery("Product"))
+		So(err, ShouldBeNil)
+		_, err = items.Resolve(NewFieldQuery("Customer"))
+		So(err, ShouldBeNil)
+		_, err = s.Items(NewFieldQuery("Customer"))
+		So(err, ShouldEqual, ErrQueryFieldMustBeSlice)
+
+		pl, _ := ParsePipeline(`Custmer | shout | upper`)
+		err = s.Check(pl, nil)
+		So(errors.Is(err, ErrInvalidField), ShouldBeTrue)
+		So(errors.Is(err, ErrUnknownFormatter), ShouldBeTrue)
+	})
+}
@@ -1,69 +1,434 @@
 package samdoc
 
 import (
+	"encoding"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
 )
 
 var (
-	ErrModelMustBePointerToStruct = errors.New("provided model must be a pointer to struct")
-	ErrQueryFieldMustBeStruct     = errors.New("query field must be struct")
+	ErrModelMustBePointerToStruct = errors.New("provided model must be a pointer to struct or a map")
+	ErrQueryFieldMustBeStruct     = errors.New("query field must be struct, slice, array or map")
+	ErrQueryFieldMustBeSlice      = errors.New("query field must be slice or array")
 	ErrInvalidPopIndex            = errors.New("invalid index for popping")
 	ErrInvalidField               = errors.New("invalid field")
+	ErrInvalidIndex               = errors.New("invalid index")
+	ErrIndexOutOfRange            = errors.New("index out of range")
+	ErrInvalidMapKey              = errors.New("invalid map key")
+	ErrMissingMapKey              = errors.New("missing map key")
+	ErrInvalidMethod              = errors.New("method must take no arguments and return a value and optionally an error")
+	ErrNilValue                   = errors.New("nil value")
+	ErrMethodPanic                = errors.New("method panicked")
 )
 
+// NilPolicy decides how nil pointers and interfaces are rendered
+type NilPolicy int
+
+const (
+	// NilAsEmpty renders nil values, and fields reached through them, as
+	// nothing
+	NilAsEmpty NilPolicy = iota
+	// NilAsError fails rendering them with ErrNilValue
+	NilAsError
+)
+
+// StructureOption configures how a Structure resolves queries
+type StructureOption func(*structureConfig)
+
+type structureConfig struct {
+	jsonTags bool
+	nil      NilPolicy
+}
+
+// WithNilPolicy sets how nil values are rendered, NilAsEmpty by default
+func WithNilPolicy(p NilPolicy) StructureOption {
+	return func(c *structureConfig) {
+		c.nil = p
+	}
+}
+
 type Structure struct {
-	m reflect.Value
+	m      reflect.Value
+	parent *Structure
+	cfg    *structureConfig
 }
 
-func NewStructure(model interface{}) (*Structure, error) {
+// NewStructure creates a Structure from a pointer to struct or a map, such as
+// the map[string]interface{} decoded from a JSON object. Fields of structs are
+// addressed by the name of their samdoc tag or their Go identifier, see
+// fieldsOf for the options of the tag.
+func NewStructure(model interface{}, opts ...StructureOption) (*Structure, error) {
+	cfg := new(structureConfig)
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
 	v := reflect.ValueOf(model)
+	if v.Kind() == reflect.Map {
+		return &Structure{m: v, cfg: cfg}, nil
+	}
 	if v.Kind() != reflect.Ptr {
 		return nil, ErrModelMustBePointerToStruct
 	}
 	v = reflect.Indirect(v)
-	if v.Kind() != reflect.Struct {
+	if v.Kind() != reflect.Struct && v.Kind() != reflect.Map {
 		return nil, ErrModelMustBePointerToStruct
 	}
 
-	return &Structure{m: v}, nil
+	return &Structure{m: v, cfg: cfg}, nil
 }
 
 func (s *Structure) Get(qry FieldQuery) (string, error) {
+	v, f, err := s.resolve(qry)
+	if err != nil {
+		return "", err
+	}
+	if text, ok := f.empty(v); ok {
+		return text, nil
+	}
+	return s.fieldToString(v)
+}
+
+// Value resolves the query to the value it addresses, an empty query
+// addresses the structure itself. queries that can't be resolved in a nested
+// structure are resolved in the structure it was created from. Fields are
+// followed through pointers and interfaces and a nil one resolves the query
+// to an invalid value, the rest of the query is still checked against the
+// type of the structure so that misspelled fields fail whether or not the
+// value is nil.
+func (s *Structure) Value(qry FieldQuery) (reflect.Value, error) {
+	v, _, err := s.resolve(qry)
+	return v, err
+}
+
+// resolve is Value along with the struct field the query ends at, whose
+// options apply when the value is rendered, nil when it ends elsewhere
+func (s *Structure) resolve(qry FieldQuery) (reflect.Value, *field, error) {
 	var v = s.m
+	var f *field
 	var err error
-	for len(qry) > 0 {
-		v, err = s.getFieldByName(v, qry[0])
+	for _, name := range qry {
+		if isNil(v) {
+			schema := &Schema{t: s.m.Type(), cfg: s.cfg}
+			if _, err = schema.Resolve(qry); err != nil {
+				return s.parentValue(qry, reflect.Value{}, err)
+			}
+			return reflect.Value{}, nil, nil
+		}
+		v, f, err = s.getFieldByName(v, name)
 		if err != nil {
-			return "", err
+			return s.parentValue(qry, v, err)
 		}
-		qry = qry.Pop(0)
 	}
-	return s.fieldToString(v)
+	return v, f, nil
 }
 
-func (s *Structure) getFieldByName(v reflect.Value, field string) (reflect.Value, error) {
-	var vo reflect.Value
-	if v.Kind() == reflect.Ptr {
+// parentValue resolves the query which failed with the error in the
+// structure the nested one was created from
+func (s *Structure) parentValue(qry FieldQuery, v reflect.Value, err error) (reflect.Value, *field, error) {
+	if s.parent != nil {
+		if pv, pf, perr := s.parent.resolve(qry); perr == nil {
+			return pv, pf, nil
+		}
+	}
+	return v, nil, err
+}
+
+// Items returns a nested structure for every element of the slice or array
+// addressed by the query.
+func (s *Structure) Items(qry FieldQuery) ([]*Structure, error) {
+	v, err := s.Value(qry)
+	if err != nil {
+		return nil, err
+	}
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil, nil
+	}
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, ErrQueryFieldMustBeSlice
+	}
+
+	items := make([]*Structure, v.Len())
+	for i := range items {
+		items[i] = &Structure{m: v.Index(i), parent: s, cfg: s.cfg}
+	}
+	return items, nil
+}
+
+// Truth reports whether the value addressed by the query is set, zero values,
+// nil pointers and empty strings, slices and maps are false while structs are
+// always true.
+func (s *Structure) Truth(qry FieldQuery) (bool, error) {
+	v, err := s.Value(qry)
+	if err != nil {
+		return false, err
+	}
+	return isTrue(v), nil
+}
+
+var jsonNumberType = reflect.TypeOf(json.Number(""))
+
+func isTrue(v reflect.Value) bool {
+	if !v.IsValid() {
+		return false
+	}
+	if v.Type() == jsonNumberType {
+		f, err := json.Number(v.String()).Float64()
+		return err != nil || f != 0
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		return !v.IsNil()
+	case reflect.Interface:
+		return !v.IsNil() && isTrue(v.Elem())
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.String, reflect.Chan:
+		return v.Len() > 0
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Struct:
+		return true
+	}
+	return !v.IsZero()
+}
+
+func isNil(v reflect.Value) bool {
+	if !v.IsValid() {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+// getFieldByName returns the field of a struct, the element of a slice or an
+// array at a numeric index, the element of a map with the given key or
+// otherwise the result of the exported method with the name, struct fields
+// are returned along with their options
+func (s *Structure) getFieldByName(v reflect.Value, field string) (reflect.Value, *field, error) {
+	e, f, err := s.getElement(v, field)
+	if err == nil {
+		return e, f, nil
+	}
+	if m, ok := methodByName(v, field); ok {
+		if promotedThroughNil(v, field) {
+			return reflect.Value{}, nil, nil
+		}
+		mv, err := callMethod(m, field)
+		return mv, nil, err
+	}
+	return e, nil, err
+}
+
+// promotedThroughNil reports whether the method with the name is promoted
+// from a nil embedded pointer or interface, which calling it would
+// dereference. A method declared on the struct itself with the name of a
+// promoted one is taken for the promoted one.
+func promotedThroughNil(v reflect.Value, name string) bool {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return false
+		}
 		v = v.Elem()
 	}
 	if v.Kind() != reflect.Struct {
-		return vo, ErrQueryFieldMustBeStruct
+		return false
 	}
-	f := v.FieldByName(field)
-	if !f.IsValid() {
-		return f, ErrInvalidField
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.Anonymous || !hasMethod(sf.Type, name) {
+			continue
+		}
+		if isNil(v.Field(i)) {
+			return true
+		}
+		return promotedThroughNil(v.Field(i), name)
+	}
+	return false
+}
+
+func hasMethod(t reflect.Type, name string) bool {
+	if _, ok := t.MethodByName(name); ok {
+		return true
+	}
+	if t.Kind() != reflect.Ptr && t.Kind() != reflect.Interface {
+		_, ok := reflect.PtrTo(t).MethodByName(name)
+		return ok
+	}
+	return false
+}
+
+func (s *Structure) getElement(v reflect.Value, field string) (reflect.Value, *field, error) {
+	var vo reflect.Value
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		fs := fieldsOf(v.Type(), s.cfg.jsonTags)
+		if f, ok := fs.lookup(field); ok {
+			return f.value(v), f, nil
+		}
+		for _, indx := range fs.embedded {
+			ev, err := v.FieldByIndexErr(indx)
+			if err != nil || ev.IsNil() {
+				continue
+			}
+			if e, f, err := s.getElement(ev, field); err == nil {
+				return e, f, nil
+			}
+		}
+		return vo, nil, ErrInvalidField
+	case reflect.Slice, reflect.Array:
+		i, err := strconv.Atoi(field)
+		if err != nil {
+			return vo, nil, fmt.Errorf("%w: %q", ErrInvalidIndex, field)
+		}
+		if i < 0 || i >= v.Len() {
+			return vo, nil, fmt.Errorf("%w: %d with length %d", ErrIndexOutOfRange, i, v.Len())
+		}
+		return v.Index(i), nil, nil
+	case reflect.Map:
+		key, err := mapKey(v.Type().Key(), field)
+		if err != nil {
+			return vo, nil, err
+		}
+		f := v.MapIndex(key)
+		if !f.IsValid() {
+			return f, nil, fmt.Errorf("%w: %q", ErrMissingMapKey, field)
+		}
+		return f, nil, nil
+	}
+	return vo, nil, ErrQueryFieldMustBeStruct
+}
+
+// mapKey converts a query field to a key of the given type
+func mapKey(t reflect.Type, field string) (reflect.Value, error) {
+	switch t.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(field).Convert(t), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(field, 10, t.Bits())
+		if err == nil {
+			return reflect.ValueOf(i).Convert(t), nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := strconv.ParseUint(field, 10, t.Bits())
+		if err == nil {
+			return reflect.ValueOf(i).Convert(t), nil
+		}
 	}
-	return f, nil
+	return reflect.Value{}, fmt.Errorf("%w: %q for %s", ErrInvalidMapKey, field, t)
+}
+
+// methodByName returns the exported method with the name of the value or of
+// the pointers and interfaces it's reached through, methods of nil pointers
+// and interfaces are never returned
+func methodByName(v reflect.Value, name string) (reflect.Value, bool) {
+	for {
+		if isNil(v) {
+			return reflect.Value{}, false
+		}
+		if m := v.MethodByName(name); m.IsValid() {
+			return m, true
+		}
+		if v.Kind() != reflect.Ptr && v.Kind() != reflect.Interface {
+			break
+		}
+		v = v.Elem()
+	}
+	if v.CanAddr() {
+		if m := v.Addr().MethodByName(name); m.IsValid() {
+			return m, true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// callMethod calls a method taking no arguments and returning a value and
+// optionally an error, a panic of the method is returned as an error like
+// text/template does
+func callMethod(m reflect.Value, name string) (v reflect.Value, err error) {
+	t := m.Type()
+	if t.NumIn() != 0 || t.NumOut() == 0 || t.NumOut() > 2 || t.NumOut() == 2 && t.Out(1) != errorType {
+		return reflect.Value{}, fmt.Errorf("%w: %s", ErrInvalidMethod, name)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			v, err = reflect.Value{}, fmt.Errorf("%w: %s: %v", ErrMethodPanic, name, r)
+		}
+	}()
+	out := m.Call(nil)
+	if len(out) == 2 && !out[1].IsNil() {
+		return reflect.Value{}, fmt.Errorf("%s: %w", name, out[1].Interface().(error))
+	}
+	return out[0], nil
 }
 
 func (s *Structure) fieldToString(v reflect.Value) (string, error) {
-	return fmt.Sprintf("%v", v.Interface()), nil
+	val := valueInterface(v)
+	if err := s.checkNil(val); err != nil {
+		return "", err
+	}
+	return toString(val), nil
+}
+
+// checkNil fails nil values under the NilAsError policy
+func (s *Structure) checkNil(val interface{}) error {
+	if s.cfg.nil == NilAsError && isNil(reflect.ValueOf(val)) {
+		return ErrNilValue
+	}
+	return nil
+}
+
+var (
+	stringerType      = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// valueInterface returns the value as an interface{}, values whose pointers
+// implement fmt.Stringer or encoding.TextMarshaler are returned as pointers
+// when they're addressable
+func valueInterface(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	t := v.Type()
+	if v.CanAddr() && t.Kind() != reflect.Ptr && !t.Implements(stringerType) && !t.Implements(textMarshalerType) {
+		pt := reflect.PtrTo(t)
+		if pt.Implements(stringerType) || pt.Implements(textMarshalerType) {
+			return v.Addr().Interface()
+		}
+	}
+	return v.Interface()
 }
 
 type FieldQuery []string
 
+// NewFieldQuery parses a dot separated query, a single dot addresses the
+// current structure.
+func NewFieldQuery(qry string) FieldQuery {
+	qry = strings.TrimSpace(qry)
+	if qry == "." {
+		return FieldQuery{}
+	}
+	return FieldQuery(strings.Split(qry, "."))
+}
+
 func (fq FieldQuery) Pop(indx int) FieldQuery {
 	if len(fq) == 0 {
 		return fq
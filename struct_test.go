@@ -1,6 +1,8 @@
 package samdoc
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -87,3 +89,317 @@ func TestStructure(t *testing.T) {
 
 	})
 }
+
+func TestStructureItems(t *testing.T) {
+	Convey("Test Structure: Items", t, func() {
+		type Family struct {
+			Name    string
+			Members []Person
+		}
+		var family = Family{
+			Name:    "koushki",
+			Members: []Person{{Name: "saman"}, {Name: "ali"}},
+		}
+
+		m, err := NewStructure(&family)
+		So(err, ShouldBeNil)
+		items, err := m.Items(NewFieldQuery("Members"))
+		So(err, ShouldBeNil)
+		So(items, ShouldHaveLength, 2)
+
+		name, err := items[1].Get(NewFieldQuery("Name"))
+		So(err, ShouldBeNil)
+		So(name, ShouldEqual, "ali")
+
+		lastName, err := items[1].Get(NewFieldQuery("LastName"))
+		So(err, ShouldBeNil)
+		So(lastName, ShouldEqual, "")
+
+		_, err = m.Items(NewFieldQuery("Name"))
+		So(err, ShouldEqual, ErrQueryFieldMustBeSlice)
+	})
+}
+
+func TestStructureTruth(t *testing.T) {
+	Convey("Test Structure: Truth", t, func() {
+		var saman = Person{Name: "saman"}
+
+		m, err := NewStructure(&saman)
+		So(err, ShouldBeNil)
+		for qry, truth := range map[string]bool{
+			"Name":     true,
+			"LastName": false,
+			"Cert":     false,
+			"Weight":   false,
+			"Address":  true,
+		} {
+			ok, err := m.Truth(NewFieldQuery(qry))
+			So(err, ShouldBeNil)
+			So(ok, ShouldEqual, truth)
+		}
+
+		_, err = m.Truth(NewFieldQuery("Age"))
+		So(err, ShouldEqual, ErrInvalidField)
+	})
+}
+
+func TestStructureIndexing(t *testing.T) {
+	Convey("Test Structure: Slices, Arrays And Maps", t, func() {
+		type Contact struct {
+			Phones [2]string
+			Emails []string
+			Meta   map[string]interface{}
+			Scores map[int]float64
+		}
+		var contact = Contact{
+			Phones: [2]string{"0912", "0935"},
+			Emails: []string{"saman@example.com"},
+			Meta:   map[string]interface{}{"customer_id": 42, "tags": []interface{}{"vip"}},
+			Scores: map[int]float64{1: 19.5},
+		}
+
+		m, err := NewStructure(&contact)
+		So(err, ShouldBeNil)
+		for qry, expected := range map[string]string{
+			"Phones.1":         "0935",
+			"Emails.0":         "saman@example.com",
+			"Meta.customer_id": "42",
+			"Meta.tags.0":      "vip",
+			"Scores.1":         "19.5",
+		} {
+			val, err := m.Get(NewFieldQuery(qry))
+			So(err, ShouldBeNil)
+			So(val, ShouldEqual, expected)
+		}
+
+		_, err = m.Get(NewFieldQuery("Emails.1"))
+		So(errors.Is(err, ErrIndexOutOfRange), ShouldBeTrue)
+		_, err = m.Get(NewFieldQuery("Emails.first"))
+		So(errors.Is(err, ErrInvalidIndex), ShouldBeTrue)
+		_, err = m.Get(NewFieldQuery("Meta.name"))
+		So(errors.Is(err, ErrMissingMapKey), ShouldBeTrue)
+		_, err = m.Get(NewFieldQuery("Scores.one"))
+		So(errors.Is(err, ErrInvalidMapKey), ShouldBeTrue)
+	})
+
+	Convey("Test Structure: Decoded JSON", t, func() {
+		var model map[string]interface{}
+		err := json.Unmarshal([]byte(`{"name": "saman", "items": [{"name": "pen"}, {"name": "book"}]}`), &model)
+		So(err, ShouldBeNil)
+
+		m, err := NewStructure(model)
+		So(err, ShouldBeNil)
+		name, err := m.Get(NewFieldQuery("items.1.name"))
+		So(err, ShouldBeNil)
+		So(name, ShouldEqual, "book")
+
+		items, err := m.Items(NewFieldQuery("items"))
+		So(err, ShouldBeNil)
+		So(items, ShouldHaveLength, 2)
+		name, err = items[0].Get(NewFieldQuery("name"))
+		So(err, ShouldBeNil)
+		So(name, ShouldEqual, "pen")
+
+		_, err = NewStructure([]string{})
+		So(err, ShouldEqual, ErrModelMustBePointerToStruct)
+	})
+}
+
+func TestTags(t *testing.T) {
+	type Address struct {
+		City string `samdoc:"city"`
+	}
+	type Customer struct {
+		Address
+		CustomerName string   `samdoc:"customer_name"`
+		Email        string   `json:"email"`
+		Phone        string   `samdoc:"phone,omitempty"`
+		Balance      float64  `samdoc:"balance,default=N/A"`
+		Note         string   `samdoc:"note,omitempty,default=none, yet"`
+		Tags         []string `samdoc:",omitempty"`
+		Active       bool     `samdoc:"active,default=no"`
+		Password     string   `samdoc:"-"`
+	}
+	model := &Customer{
+		Address:      Address{City: "Shiraz"},
+		CustomerName: "Ali",
+		Email:        "ali@example.com",
+		Password:     "secret",
+	}
+
+	Convey("Test Tags: Names And Options", t, func() {
+		strct, err := NewStructure(model)
+		So(err, ShouldBeNil)
+
+		get := func(qry string) string {
+			s, err := strct.Get(NewFieldQuery(qry))
+			So(err, ShouldBeNil)
+			return s
+		}
+		So(get("customer_name"), ShouldEqual, "Ali")
+		So(get("CustomerName"), ShouldEqual, "Ali")
+		So(get("city"), ShouldEqual, "Shiraz")
+		So(get("Address.city"), ShouldEqual, "Shiraz")
+		So(get("phone"), ShouldEqual, "")
+		So(get("balance"), ShouldEqual, "N/A")
+		So(get("note"), ShouldEqual, "none, yet")
+		So(get("Tags"), ShouldEqual, "[]")
+
+		_, err = strct.Get(NewFieldQuery("Password"))
+		So(err, ShouldEqual, ErrInvalidField)
+		_, err = strct.Get(NewFieldQuery("email"))
+		So(err, ShouldEqual, ErrInvalidField)
+
+		So(get("active"), ShouldEqual, "no")
+
+		// defaults only replace the rendered text, conditions see the value
+		for _, qry := range []string{"balance", "active", "note"} {
+			ok, err := strct.Truth(NewFieldQuery(qry))
+			So(err, ShouldBeNil)
+			So(ok, ShouldBeFalse)
+		}
+		pl, _ := ParsePipeline("active | upper")
+		s, err := strct.Execute(pl, nil)
+		So(err, ShouldBeNil)
+		So(s, ShouldEqual, "NO")
+	})
+
+	Convey("Test Tags: JSON Fallback", t, func() {
+		strct, err := NewStructure(model, WithJSONTags())
+		So(err, ShouldBeNil)
+
+		s, err := strct.Get(NewFieldQuery("email"))
+		So(err, ShouldBeNil)
+		So(s, ShouldEqual, "ali@example.com")
+
+		items, err := strct.Items(NewFieldQuery("Tags"))
+		So(err, ShouldBeNil)
+		So(items, ShouldBeEmpty)
+	})
+}
+
+type Named interface {
+	Title() string
+}
+
+type Employee struct {
+	Named
+	*Person
+	Manager  *Employee
+	Salary   Salary
+	Contract Contract
+}
+
+type Salary int
+
+func (s *Salary) String() string {
+	return fmt.Sprintf("%d IRR", int(*s))
+}
+
+type Contract struct {
+	Code string
+}
+
+func (c Contract) MarshalText() ([]byte, error) {
+	return []byte("C-" + c.Code), nil
+}
+
+type Role struct {
+	Name string
+}
+
+func (r Role) Title() string {
+	return "Role " + r.Name
+}
+
+func (p Person) FullName() string {
+	return p.Name + " " + p.LastName
+}
+
+func (p *Person) Initials() (string, error) {
+	if p.Name == "" || p.LastName == "" {
+		return "", errors.New("missing name")
+	}
+	return p.Name[:1] + p.LastName[:1], nil
+}
+
+func (p Person) Badge() string {
+	return p.Cert.Image
+}
+
+func (p Person) Greet(greeting string) string {
+	return greeting + " " + p.Name
+}
+
+func TestValueResolution(t *testing.T) {
+	model := &Employee{
+		Named:    &Role{Name: "admin"},
+		Person:   &Person{Name: "saman", LastName: "koushki", Cert: &Cert{NIN: NIN{f: "1", s: "2"}}},
+		Salary:   1200,
+		Contract: Contract{Code: "42"},
+	}
+
+	Convey("Test Value Resolution: Methods, Stringers And Embedding", t, func() {
+		strct, err := NewStructure(model)
+		So(err, ShouldBeNil)
+
+		get := func(qry string) string {
+			s, err := strct.Get(NewFieldQuery(qry))
+			So(err, ShouldBeNil)
+			return s
+		}
+		So(get("FullName"), ShouldEqual, "saman koushki")
+		So(get("Person.Initials"), ShouldEqual, "sk")
+		So(get("Title"), ShouldEqual, "Role admin")
+		So(get("Named.Name"), ShouldEqual, "admin")
+		So(get("Name"), ShouldEqual, "saman")
+		So(get("Cert.NIN"), ShouldEqual, "12")
+		So(get("Salary"), ShouldEqual, "1200 IRR")
+		So(get("Contract"), ShouldEqual, "C-42")
+
+		_, err = strct.Get(NewFieldQuery("Greet"))
+		So(errors.Is(err, ErrInvalidMethod), ShouldBeTrue)
+
+		strct, err = NewStructure(&Employee{Person: &Person{Name: "saman"}})
+		So(err, ShouldBeNil)
+		_, err = strct.Get(NewFieldQuery("Initials"))
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldEqual, "Initials: missing name")
+	})
+
+	Convey("Test Value Resolution: Nil Policy", t, func() {
+		strct, err := NewStructure(&Employee{})
+		So(err, ShouldBeNil)
+
+		for _, qry := range []string{"Manager", "Manager.Name", "Name", "Cert.Born", "FullName", "Title"} {
+			s, err := strct.Get(NewFieldQuery(qry))
+			So(err, ShouldBeNil)
+			So(s, ShouldEqual, "")
+		}
+		ok, err := strct.Truth(NewFieldQuery("Manager.Manager"))
+		So(err, ShouldBeNil)
+		So(ok, ShouldBeFalse)
+
+		for _, qry := range []string{"Manager.Person.Nmae", "Manager.Manager.Contract.Cdoe", "Cert.Bron"} {
+			_, err = strct.Get(NewFieldQuery(qry))
+			So(err, ShouldEqual, ErrInvalidField)
+		}
+
+		strct, err = NewStructure(&Employee{}, WithNilPolicy(NilAsError))
+		So(err, ShouldBeNil)
+		_, err = strct.Get(NewFieldQuery("Manager.Name"))
+		So(err, ShouldEqual, ErrNilValue)
+
+		pl, _ := ParsePipeline(`Manager | default "-"`)
+		s, err := strct.Execute(pl, nil)
+		So(err, ShouldBeNil)
+		So(s, ShouldEqual, "-")
+
+		// nil dereferences of methods themselves are errors, not nil values
+		strct, err = NewStructure(&Employee{Person: &Person{}})
+		So(err, ShouldBeNil)
+		_, err = strct.Get(NewFieldQuery("Badge"))
+		So(errors.Is(err, ErrMethodPanic), ShouldBeTrue)
+		So(err.Error(), ShouldContainSubstring, "Badge")
+	})
+}
@@ -0,0 +1,142 @@
+package samdoc
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// WithJSONTags makes the name of the json tag of fields without a samdoc tag
+// address them as well
+func WithJSONTags() StructureOption {
+	return func(c *structureConfig) {
+		c.jsonTags = true
+	}
+}
+
+// field is a field of a struct type along with the options of its tag
+type field struct {
+	index     []int
+	omitEmpty bool
+	// def replaces the value of the field when it's rendered empty, see
+	// isTrue
+	def    string
+	hasDef bool
+}
+
+// structFields are the fields of a struct type by the names queries address
+// them with, tag names take precedence over Go identifiers
+type structFields struct {
+	byTag  map[string]*field
+	byName map[string]*field
+	// embedded are the embedded interfaces, fields missing from the struct
+	// are looked up in their dynamic values
+	embedded [][]int
+}
+
+type fieldsKey struct {
+	t        reflect.Type
+	jsonTags bool
+}
+
+var fieldsCache sync.Map
+
+// fieldsOf returns the fields of a struct type, fields and their tags are
+// parsed once per type.
+//
+//	CustomerName string  `samdoc:"customer_name"`
+//	Nickname     string  `samdoc:",omitempty"`
+//	Total        float64 `samdoc:"total,default=N/A"`
+//	Secret       string  `samdoc:"-"`
+//
+// The default option takes the rest of the tag, commas included, so it has
+// to be the last one.
+func fieldsOf(t reflect.Type, jsonTags bool) *structFields {
+	key := fieldsKey{t: t, jsonTags: jsonTags}
+	if fs, ok := fieldsCache.Load(key); ok {
+		return fs.(*structFields)
+	}
+
+	fs := &structFields{byTag: map[string]*field{}, byName: map[string]*field{}}
+	for _, sf := range reflect.VisibleFields(t) {
+		if !sf.IsExported() {
+			continue
+		}
+		if sf.Anonymous && sf.Type.Kind() == reflect.Interface {
+			fs.embedded = append(fs.embedded, sf.Index)
+		}
+		tag, ok := sf.Tag.Lookup("samdoc")
+		if !ok && jsonTags {
+			tag, ok = sf.Tag.Lookup("json")
+			// json options have no meaning for templates
+			tag, _, _ = strings.Cut(tag, ",")
+		}
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		f := &field{index: sf.Index}
+		for opts != "" {
+			var opt string
+			if strings.HasPrefix(opts, "default=") {
+				f.def, f.hasDef = strings.TrimPrefix(opts, "default="), true
+				break
+			}
+			opt, opts, _ = strings.Cut(opts, ",")
+			if opt == "omitempty" {
+				f.omitEmpty = true
+			}
+		}
+
+		if ok && name != "" {
+			addField(fs.byTag, name, f)
+		}
+		addField(fs.byName, sf.Name, f)
+	}
+
+	actual, _ := fieldsCache.LoadOrStore(key, fs)
+	return actual.(*structFields)
+}
+
+// addField adds the field unless a shallower one has the same name
+func addField(fields map[string]*field, name string, f *field) {
+	if prev, ok := fields[name]; ok && len(prev.index) <= len(f.index) {
+		return
+	}
+	fields[name] = f
+}
+
+// lookup returns the field with the tag name or otherwise the Go identifier
+func (fs *structFields) lookup(name string) (*field, bool) {
+	if f, ok := fs.byTag[name]; ok {
+		return f, true
+	}
+	f, ok := fs.byName[name]
+	return f, ok
+}
+
+// value returns the field of the struct, fields promoted through a nil
+// embedded pointer are nil
+func (f *field) value(v reflect.Value) reflect.Value {
+	fv, err := v.FieldByIndexErr(f.index)
+	if err != nil {
+		return reflect.Value{}
+	}
+	return fv
+}
+
+// empty returns the text the value of the field is rendered as when it's
+// empty, the default value or an empty string under the omitempty option.
+// Conditions and ranges see the value itself, and slices, arrays and maps
+// are never replaced.
+func (f *field) empty(v reflect.Value) (string, bool) {
+	if f == nil || !f.hasDef && !f.omitEmpty || isTrue(v) {
+		return "", false
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return "", false
+	}
+	return f.def, true
+}
@@ -0,0 +1,177 @@
+package samdoc
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrNumberTooLarge      = errors.New("number too large to spell out")
+	ErrUnsupportedLanguage = errors.New("unsupported language")
+)
+
+// SpelledNumber is a number spelled out by the words formatter, Value is its
+// plain decimal representation. It's spelled out in the language of the
+// locale a template is executed with, English if the language isn't supported.
+type SpelledNumber struct {
+	Value string
+}
+
+func (n SpelledNumber) String() string {
+	s, err := SpellNumber(n.Value, English.Tag)
+	if err != nil {
+		return n.Value
+	}
+	return s
+}
+
+type speller struct {
+	zero     string
+	minus    string
+	ones     [10]string
+	teens    [10]string
+	tens     [10]string
+	hundreds [10]string
+	scales   []string
+	// and joins the groups of three digits and the parts of a group
+	and string
+	// tensAnd joins the tens and the ones of a group
+	tensAnd string
+	// fraction spells out the decimals of a number
+	fraction func(sp *speller, integer string, decimals string) string
+}
+
+var spellers = map[string]*speller{
+	"en": {
+		zero:  "zero",
+		minus: "minus",
+		ones:  [10]string{"", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine"},
+		teens: [10]string{"ten", "eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen", "seventeen", "eighteen", "nineteen"},
+		tens:  [10]string{"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety"},
+		hundreds: [10]string{"", "one hundred", "two hundred", "three hundred", "four hundred",
+			"five hundred", "six hundred", "seven hundred", "eight hundred", "nine hundred"},
+		scales: []string{"", "thousand", "million", "billion", "trillion", "quadrillion", "quintillion",
+			"sextillion", "septillion", "octillion", "nonillion", "decillion"},
+		and:     " ",
+		tensAnd: "-",
+		fraction: func(sp *speller, integer string, decimals string) string {
+			ds := make([]string, 0, len(decimals))
+			for _, d := range decimals {
+				if d == '0' {
+					ds = append(ds, sp.zero)
+				} else {
+					ds = append(ds, sp.ones[d-'0'])
+				}
+			}
+			return integer + " point " + strings.Join(ds, " ")
+		},
+	},
+	"fa": {
+		zero:  "صفر",
+		minus: "منفی",
+		ones:  [10]string{"", "یک", "دو", "سه", "چهار", "پنج", "شش", "هفت", "هشت", "نه"},
+		teens: [10]string{"ده", "یازده", "دوازده", "سیزده", "چهارده", "پانزده", "شانزده", "هفده", "هجده", "نوزده"},
+		tens:  [10]string{"", "", "بیست", "سی", "چهل", "پنجاه", "شصت", "هفتاد", "هشتاد", "نود"},
+		hundreds: [10]string{"", "صد", "دویست", "سیصد", "چهارصد",
+			"پانصد", "ششصد", "هفتصد", "هشتصد", "نهصد"},
+		scales: []string{"", "هزار", "میلیون", "میلیارد", "تریلیون", "کوادریلیون", "کوینتیلیون",
+			"سکستیلیون", "سپتیلیون", "اکتیلیون", "نونیلیون", "دسیلیون"},
+		and:     " و ",
+		tensAnd: " و ",
+		fraction: func(sp *speller, integer string, decimals string) string {
+			denominators := []string{"دهم", "صدم", "هزارم", "ده‌هزارم", "صدهزارم",
+				"میلیونیم", "ده‌میلیونیم", "صدمیلیونیم", "میلیاردیم"}
+			numerator, _ := sp.integer(strings.TrimLeft(decimals, "0"))
+			if len(decimals) > len(denominators) {
+				return integer + " ممیز " + numerator
+			}
+			fraction := numerator + " " + denominators[len(decimals)-1]
+			if integer == sp.zero {
+				return fraction
+			}
+			return integer + " و " + fraction
+		},
+	},
+}
+
+// SpellNumber spells out a plain decimal number, such as -1200000.5, in the
+// language of the tag, English and Persian are supported.
+//
+//	SpellNumber("1200000", "fa") // یک میلیون و دویست هزار
+func SpellNumber(number string, tag string) (string, error) {
+	l, ok := LookupLocale(tag)
+	if !ok {
+		return "", ErrUnsupportedLanguage
+	}
+	sp, ok := spellers[l.Tag]
+	if !ok {
+		return "", ErrUnsupportedLanguage
+	}
+
+	negative := strings.HasPrefix(number, "-")
+	number = strings.TrimPrefix(number, "-")
+	integer, decimals, _ := strings.Cut(number, ".")
+	integer = strings.TrimLeft(integer, "0")
+	if strings.Trim(integer+decimals, "0123456789") != "" {
+		return "", ErrFormatterValue
+	}
+
+	s, err := sp.integer(integer)
+	if err != nil {
+		return "", err
+	}
+	if strings.Trim(decimals, "0") != "" {
+		s = sp.fraction(sp, s, decimals)
+	}
+	if negative && strings.Trim(integer+decimals, "0") != "" {
+		s = sp.minus + " " + s
+	}
+	return s, nil
+}
+
+// integer spells out a non negative integer without leading zeros
+func (sp *speller) integer(n string) (string, error) {
+	if n == "" {
+		return sp.zero, nil
+	}
+	groups := (len(n) + 2) / 3
+	if groups > len(sp.scales) {
+		return "", ErrNumberTooLarge
+	}
+	n = strings.Repeat("0", groups*3-len(n)) + n
+
+	var parts []string
+	for g := 0; g < groups; g++ {
+		group := n[g*3 : g*3+3]
+		if group == "000" {
+			continue
+		}
+		part := sp.group(group)
+		if scale := sp.scales[groups-g-1]; scale != "" {
+			part += " " + scale
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, sp.and), nil
+}
+
+// group spells out three digits
+func (sp *speller) group(g string) string {
+	h, t, o := g[0]-'0', g[1]-'0', g[2]-'0'
+
+	var parts []string
+	if h != 0 {
+		parts = append(parts, sp.hundreds[h])
+	}
+	switch {
+	case t == 1:
+		parts = append(parts, sp.teens[o])
+	case t > 1 && o != 0:
+		parts = append(parts, sp.tens[t]+sp.tensAnd+sp.ones[o])
+	case t > 1:
+		parts = append(parts, sp.tens[t])
+	case o != 0:
+		parts = append(parts, sp.ones[o])
+	}
+	return strings.Join(parts, sp.and)
+}
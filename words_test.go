@@ -0,0 +1,93 @@
+package samdoc
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWords(t *testing.T) {
+	Convey("Test Words: English", t, func() {
+		cases := map[string]string{
+			"0":       "zero",
+			"7":       "seven",
+			"15":      "fifteen",
+			"40":      "forty",
+			"99":      "ninety-nine",
+			"105":     "one hundred five",
+			"1000":    "one thousand",
+			"1200000": "one million two hundred thousand",
+			"2000017": "two million seventeen",
+			"-21":     "minus twenty-one",
+			"12.05":   "twelve point zero five",
+			"0.50":    "zero point five zero",
+			"3.000":   "three",
+		}
+		for n, expected := range cases {
+			s, err := SpellNumber(n, "en")
+			So(err, ShouldBeNil)
+			So(s, ShouldEqual, expected)
+		}
+	})
+
+	Convey("Test Words: Persian", t, func() {
+		cases := map[string]string{
+			"0":       "صفر",
+			"13":      "سیزده",
+			"21":      "بیست و یک",
+			"305":     "سیصد و پنج",
+			"1000":    "یک هزار",
+			"1200000": "یک میلیون و دویست هزار",
+			"1002003": "یک میلیون و دو هزار و سه",
+			"-5":      "منفی پنج",
+			"12.5":    "دوازده و پنج دهم",
+			"0.25":    "بیست و پنج صدم",
+			"3.125":   "سه و صد و بیست و پنج هزارم",
+		}
+		for n, expected := range cases {
+			s, err := SpellNumber(n, "fa-IR")
+			So(err, ShouldBeNil)
+			So(s, ShouldEqual, expected)
+		}
+	})
+
+	Convey("Test Words: Errors", t, func() {
+		_, err := SpellNumber("12", "ar")
+		So(err, ShouldEqual, ErrUnsupportedLanguage)
+		_, err = SpellNumber("1e5", "en")
+		So(err, ShouldEqual, ErrFormatterValue)
+		_, err = SpellNumber("1"+strings.Repeat("0", 36), "en")
+		So(err, ShouldEqual, ErrNumberTooLarge)
+	})
+
+	Convey("Test Words: Formatter And Locales", t, func() {
+		type Invoice struct {
+			Total    int64
+			Discount float64
+			Big      *big.Int
+		}
+		strct, err := NewStructure(&Invoice{Total: 1200000, Discount: 2.5, Big: big.NewInt(3000)})
+		So(err, ShouldBeNil)
+
+		eval := func(text string) interface{} {
+			pl, err := ParsePipeline(text)
+			So(err, ShouldBeNil)
+			v, err := strct.Evaluate(pl, nil)
+			So(err, ShouldBeNil)
+			return v
+		}
+
+		So(eval(`Total | words "fa"`), ShouldEqual, "یک میلیون و دویست هزار")
+		So(Persian.Sprint(eval(`Total | words`)), ShouldEqual, "یک میلیون و دویست هزار")
+		So(English.Sprint(eval(`Discount | words`)), ShouldEqual, "two point five")
+		So(Arabic.Sprint(eval(`Big | words`)), ShouldEqual, "three thousand")
+		So(toString(eval(`Total | words`)), ShouldEqual, "one million two hundred thousand")
+		So(Persian.Sprint(eval(`Total | number 2 | words`)), ShouldEqual, "یک میلیون و دویست هزار")
+
+		pl, _ := ParsePipeline(`Total | words "xx"`)
+		_, err = strct.Evaluate(pl, nil)
+		So(err, ShouldWrap, ErrUnsupportedLanguage)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"regexp"
+	"strings"
 )
 
 // ---------------------
@@ -14,7 +15,7 @@ var (
 	start_tag_reg        = regexp.MustCompile(`^[ \n\t]*?<([^\/?]*?[a-zA-Z:\d]+)((?: {0,}[a-zA-Z:\d\-]+?="[^"]*?")+|)(?:[^\/]|)>`)
 	first_start_tag_reg  = regexp.MustCompile(`(?s)^.{0,}?<([^/?]*?[a-zA-Z:\d]+)((?: {0,}[a-zA-Z:\d\-]+?="[^"]*?")+|)(?:[^/]|)>`)
 	end_tag_reg          = regexp.MustCompile(`^</([a-zA-Z:\d]+?)>`)
-	self_closing_tag_reg = regexp.MustCompile(`(?s)^[ \n\t]*?(<.+?/>)`)
+	self_closing_tag_reg = regexp.MustCompile(`(?s)^<([a-zA-Z:\d\-_.]+)((?:\s+[a-zA-Z:\d\-]+?="[^"]*?")*)\s*/>(.*)$`)
 	chardata_reg         = regexp.MustCompile(`(?s)^(.+?)(?:<)`)
 	attrs_reg            = regexp.MustCompile(`(?s)(\S+)="(.*?)"`)
 	default_indent       = " "
@@ -195,6 +196,14 @@ func (xp *XMLEncoder) formatCharData(d CharData) error {
 	return nil
 }
 
+// formatEmptyTag writes an element without content as a self closing tag,
+// without the line break of start tags so the white space kept in the data
+// of its parent doesn't grow every time the element is read and written
+func (xp *XMLEncoder) formatEmptyTag(t StartTag) error {
+	xp.w = append(xp.w, []byte(strings.TrimSuffix(t.String(), ">")+"/>")...)
+	return nil
+}
+
 func (xp *XMLEncoder) formatEndTag(t EndTag) error {
 	xp.w = append(xp.w, []byte(t.String())...)
 	return nil
@@ -234,6 +243,16 @@ func (u *UniversalElement) XMLUnmarshal(e *XMLDecoder, start StartTag) error {
 			}
 			u.Children = append(u.Children, uniel)
 		case CharData:
+			// the decoder returns self closing tags as char data, they're
+			// made children to keep their place among the other children
+			if m := self_closing_tag_reg.FindStringSubmatch(string(tt)); m != nil {
+				u.Children = append(u.Children, &UniversalElement{
+					XMLName: m[1],
+					Attrs:   e.parseAttrsFromString(m[2]),
+				})
+				u.Data += m[3]
+				continue
+			}
 			u.Data += string(tt)
 		case EndTag:
 			return nil
@@ -250,6 +269,9 @@ func (u *UniversalElement) XMLMarshal(e *XMLEncoder) error {
 		Tagname: u.XMLName,
 		Attrs:   u.Attrs,
 	}
+	if u.Data == "" && len(u.Children) == 0 {
+		return e.formatEmptyTag(t)
+	}
 	err := e.EncodeToken(t)
 	if err != nil {
 		return err
@@ -299,3 +321,25 @@ func (u *UniversalElement) GetElementByName(name string) *UniversalElement {
 	}
 	return nil
 }
+
+// Clone returns a deep copy of the element and all of its children
+func (u *UniversalElement) Clone() *UniversalElement {
+	if u == nil {
+		return nil
+	}
+	c := &UniversalElement{
+		XMLName: u.XMLName,
+		Data:    u.Data,
+	}
+	if u.Attrs != nil {
+		c.Attrs = make([][2]string, len(u.Attrs))
+		copy(c.Attrs, u.Attrs)
+	}
+	if u.Children != nil {
+		c.Children = make([]*UniversalElement, len(u.Children))
+		for i, child := range u.Children {
+			c.Children[i] = child.Clone()
+		}
+	}
+	return c
+}
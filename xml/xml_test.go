@@ -89,6 +89,34 @@ func TestXML(t *testing.T) {
 
 }
 
+func TestSelfClosingElements(t *testing.T) {
+	Convey("Test xml: self closing elements keep their place", t, func() {
+		data := `<w:r>
+<w:rPr><w:b/></w:rPr><w:t>a</w:t><w:tab/><w:t>b</w:t>
+<w:br w:type="page"/>
+</w:r>`
+		var r UniversalElement
+		So(Unmarshal([]byte(data), &r), ShouldBeNil)
+		var names []string
+		for _, c := range r.Children {
+			names = append(names, c.XMLName)
+		}
+		So(names, ShouldResemble, []string{"w:rPr", "w:t", "w:tab", "w:t", "w:br"})
+		So(r.Children[4].Attrs, ShouldResemble, [][2]string{{"w:type", "page"}})
+		So(r.GetElementByName("w:rPr").GetElementByName("w:b"), ShouldNotBeNil)
+		So(r.Data, ShouldEqual, "\n")
+
+		b, err := Marshal(&r)
+		So(err, ShouldBeNil)
+		So(string(b), ShouldEndWith, "\n<w:r>\n\n<w:rPr><w:b/></w:rPr>\n<w:t>a</w:t><w:tab/>\n<w:t>b</w:t><w:br w:type=\"page\"/></w:r>")
+
+		// and reading what was written gives the same element
+		var again UniversalElement
+		So(Unmarshal(b, &again), ShouldBeNil)
+		So(again.Children, ShouldResemble, r.Children)
+	})
+}
+
 func TestHtml(t *testing.T) {
 	var SimpleHtmlTemplate = `
 	<html>